@@ -0,0 +1,176 @@
+package util
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// responseCodeRegex matches the status code line of an error response
+// formatted by the Vault API client.
+var responseCodeRegex = regexp.MustCompile(`(?m)^Code: (\d{3})\.`)
+
+// ErrorClass is a broad classification of an error returned by Vault.
+type ErrorClass int
+
+const (
+	ErrorClassUnknown ErrorClass = iota
+	ErrorClassNotFound
+	ErrorClassPermissionDenied
+	ErrorClassSealed
+	ErrorClassStandby
+	ErrorClassRateLimited
+	ErrorClassTransient
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassNotFound:
+		return "not found"
+	case ErrorClassPermissionDenied:
+		return "permission denied"
+	case ErrorClassSealed:
+		return "sealed"
+	case ErrorClassStandby:
+		return "standby"
+	case ErrorClassRateLimited:
+		return "rate limited"
+	case ErrorClassTransient:
+		return "transient"
+	default:
+		return "unknown"
+	}
+}
+
+// ResponseError is an error response returned by the Vault API.
+type ResponseError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Message is the body of the error, after the status code.
+	Message string
+
+	err error
+}
+
+func (e *ResponseError) Error() string {
+	return e.err.Error()
+}
+
+// AsResponseError returns the Vault API error response described by err,
+// or nil if err did not come from an error response. The vendored Vault API
+// client has no api.ResponseError type and reports error responses as
+// formatted strings, so the status code is parsed from the "Code: NNN" line
+// they always include.
+func AsResponseError(err error) *ResponseError {
+	if err == nil {
+		return nil
+	}
+	if respErr, ok := err.(*ResponseError); ok {
+		return respErr
+	}
+
+	msg := err.Error()
+	loc := responseCodeRegex.FindStringSubmatchIndex(msg)
+	if loc == nil {
+		return nil
+	}
+	code, convErr := strconv.Atoi(msg[loc[2]:loc[3]])
+	if convErr != nil {
+		return nil
+	}
+
+	return &ResponseError{
+		StatusCode: code,
+		Message:    strings.TrimSpace(msg[loc[1]:]),
+		err:        err,
+	}
+}
+
+// StatusCode returns the HTTP status code of the Vault API error response
+// described by err, or 0 if err did not come from an error response.
+func StatusCode(err error) int {
+	if respErr := AsResponseError(err); respErr != nil {
+		return respErr.StatusCode
+	}
+	return 0
+}
+
+// ClassifyError sorts an error returned by the Vault API into one of the
+// broad classes that callers act upon.
+func ClassifyError(err error) ErrorClass {
+	respErr := AsResponseError(err)
+	if respErr == nil {
+		return ErrorClassUnknown
+	}
+
+	msg := strings.ToLower(respErr.Message)
+	switch code := respErr.StatusCode; {
+	case code == http.StatusNotFound:
+		return ErrorClassNotFound
+	case isMissingAccessorMessage(msg):
+		// Looking up an accessor that doesn't exist, such as one of an
+		// expired token or SecretID, fails with a 400 or a 500 instead.
+		return ErrorClassNotFound
+	case code == http.StatusForbidden:
+		return ErrorClassPermissionDenied
+	case code == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case code == http.StatusServiceUnavailable && strings.Contains(msg, "sealed"):
+		return ErrorClassSealed
+	case code >= 500 && (strings.Contains(msg, "standby") || strings.Contains(msg, "not active")):
+		return ErrorClassStandby
+	case code == http.StatusPreconditionFailed, code >= 500 && code != http.StatusNotImplemented:
+		return ErrorClassTransient
+	}
+
+	return ErrorClassUnknown
+}
+
+// isMissingAccessorMessage reports whether msg is the error Vault returns
+// for an accessor that doesn't exist.
+func isMissingAccessorMessage(msg string) bool {
+	return strings.Contains(msg, "invalid accessor") || strings.Contains(msg, "failed to find accessor entry")
+}
+
+// IsNotFound reports whether err is a 404 response from Vault, or a lookup
+// of an accessor that doesn't exist.
+func IsNotFound(err error) bool {
+	return ClassifyError(err) == ErrorClassNotFound
+}
+
+// IsPermissionDenied reports whether err is a 403 response from Vault.
+func IsPermissionDenied(err error) bool {
+	return ClassifyError(err) == ErrorClassPermissionDenied
+}
+
+// IsSealed reports whether err was returned because Vault is sealed.
+func IsSealed(err error) bool {
+	return ClassifyError(err) == ErrorClassSealed
+}
+
+// IsStandby reports whether err was returned by a standby node that could
+// not service the request.
+func IsStandby(err error) bool {
+	return ClassifyError(err) == ErrorClassStandby
+}
+
+// IsRateLimited reports whether err is a 429 response from Vault.
+func IsRateLimited(err error) bool {
+	return ClassifyError(err) == ErrorClassRateLimited
+}
+
+// IsTransient reports whether err is a server-side failure that is likely
+// to succeed if the request is retried.
+func IsTransient(err error) bool {
+	return ClassifyError(err) == ErrorClassTransient
+}
+
+// IsCheckAndSetMismatch reports whether err was returned because a write to
+// a KV version 2 secret gave a cas version other than the current one.
+func IsCheckAndSetMismatch(err error) bool {
+	respErr := AsResponseError(err)
+	return respErr != nil && respErr.StatusCode == http.StatusBadRequest &&
+		strings.Contains(respErr.Message, "check-and-set parameter did not match")
+}
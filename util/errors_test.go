@@ -0,0 +1,80 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func testResponseError(code int, errs string) error {
+	return fmt.Errorf("Error making API request.\n\n"+
+		"URL: GET https://127.0.0.1:8200/v1/secret/foo\n"+
+		"Code: %d. Errors:\n\n%s", code, errs)
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		class  ErrorClass
+	}{
+		{"Nil", nil, 0, ErrorClassUnknown},
+		{"NotAnAPIError", fmt.Errorf("Code: 404 somewhere in a message"), 0, ErrorClassUnknown},
+		{"NotFound", testResponseError(404, ""), 404, ErrorClassNotFound},
+		{"PermissionDenied", testResponseError(403, "* permission denied"), 403, ErrorClassPermissionDenied},
+		{"BadRequest", testResponseError(400, "* missing client token"), 400, ErrorClassUnknown},
+		{"InvalidAccessor", testResponseError(400, "* invalid accessor"), 400, ErrorClassNotFound},
+		{"MissingSecretIDAccessor", testResponseError(500, "* failed to find accessor entry for secret_id_accessor: \"abc\""), 500, ErrorClassNotFound},
+		{"Sealed", testResponseError(503, "* Vault is sealed"), 503, ErrorClassSealed},
+		{"Standby", testResponseError(500, "* node is in standby mode"), 500, ErrorClassStandby},
+		{"NotActive", testResponseError(503, "* local node not active but active cluster node not found"), 503, ErrorClassStandby},
+		{"RateLimited", testResponseError(429, "* request path \"secret/foo\": rate limit quota exceeded"), 429, ErrorClassRateLimited},
+		{"PreconditionFailed", testResponseError(412, "* required index state not present"), 412, ErrorClassTransient},
+		{"InternalError", testResponseError(500, "* internal error"), 500, ErrorClassTransient},
+		{"BadGateway", testResponseError(502, ""), 502, ErrorClassTransient},
+		{"NotImplemented", testResponseError(501, "* unsupported operation"), 501, ErrorClassUnknown},
+		{"RawMessage", fmt.Errorf("Error making API request.\n\nURL: GET https://127.0.0.1:8200/v1/secret/foo\nCode: 504. Raw Message:\n\ngateway timeout"), 504, ErrorClassTransient},
+		{"Typed", &ResponseError{StatusCode: 404, err: fmt.Errorf("not found")}, 404, ErrorClassNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := StatusCode(tc.err); got != tc.status {
+				t.Errorf("bad status code: want %d, got %d", tc.status, got)
+			}
+			if got := ClassifyError(tc.err); got != tc.class {
+				t.Errorf("bad error class: want %q, got %q", tc.class, got)
+			}
+		})
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	if !IsNotFound(testResponseError(404, "")) {
+		t.Errorf("404 should be not found")
+	}
+	if IsNotFound(testResponseError(403, "* permission denied")) {
+		t.Errorf("403 shouldn't be not found")
+	}
+	if !IsPermissionDenied(testResponseError(403, "* permission denied")) {
+		t.Errorf("403 should be permission denied")
+	}
+	if !IsSealed(testResponseError(503, "* Vault is sealed")) {
+		t.Errorf("should be sealed")
+	}
+	if !IsStandby(testResponseError(500, "* node is in standby mode")) {
+		t.Errorf("should be standby")
+	}
+	if !IsRateLimited(testResponseError(429, "")) {
+		t.Errorf("429 should be rate limited")
+	}
+	if !IsTransient(testResponseError(503, "* upstream unavailable")) {
+		t.Errorf("503 should be transient")
+	}
+	if !IsCheckAndSetMismatch(testResponseError(400, "* check-and-set parameter did not match the current version")) {
+		t.Errorf("should be a check-and-set mismatch")
+	}
+	if IsCheckAndSetMismatch(testResponseError(400, "* check-and-set parameter required for this call")) {
+		t.Errorf("a missing cas parameter shouldn't be a check-and-set mismatch")
+	}
+}
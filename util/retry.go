@@ -0,0 +1,65 @@
+package util
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+// RetryWithBackoff calls f until it succeeds, returns a non-retryable error,
+// or has been retried maxRetries times. Like resource.Retry, f reports
+// whether its error can be retried by returning a *resource.RetryError, but
+// attempts are bounded by count rather than by time, and are separated by
+// an exponential backoff between minBackoff and maxBackoff. Waiting for the
+// next attempt stops, with the error of ctx, when ctx is done.
+func RetryWithBackoff(ctx context.Context, maxRetries int, minBackoff, maxBackoff time.Duration, f func() *resource.RetryError) error {
+	for attempt := 0; ; attempt++ {
+		rerr := f()
+		if rerr == nil {
+			return nil
+		}
+		if !rerr.Retryable || attempt >= maxRetries {
+			return rerr.Err
+		}
+
+		timer := time.NewTimer(Backoff(minBackoff, maxBackoff, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff returns how long to wait before retrying for the given attempt,
+// starting from 0. The wait doubles with each attempt, is capped at max, and
+// has up to a quarter of it randomly shaved off so that concurrent callers
+// don't retry in lockstep.
+func Backoff(min, max time.Duration, attempt int) time.Duration {
+	wait := min
+	for i := 0; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	if jitter := int64(wait / 4); jitter > 0 {
+		wait -= time.Duration(rand.Int63n(jitter))
+	}
+	if wait < min {
+		wait = min
+	}
+	return wait
+}
+
+// ValidateDuration checks that a schema value can be parsed with
+// time.ParseDuration.
+func ValidateDuration(v interface{}, k string) ([]string, []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		return nil, []error{err}
+	}
+	return nil, nil
+}
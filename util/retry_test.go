@@ -0,0 +1,88 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestRetryWithBackoff(t *testing.T) {
+	retryErr := errors.New("retry me")
+	fatalErr := errors.New("give up")
+
+	tests := []struct {
+		name          string
+		maxRetries    int
+		results       []*resource.RetryError
+		expectErr     error
+		expectedCalls int
+	}{
+		{"Success", 2, []*resource.RetryError{nil}, nil, 1},
+		{"RetryThenSuccess", 2, []*resource.RetryError{resource.RetryableError(retryErr), nil}, nil, 2},
+		{"Exhausted", 2, []*resource.RetryError{
+			resource.RetryableError(retryErr),
+			resource.RetryableError(retryErr),
+			resource.RetryableError(retryErr),
+		}, retryErr, 3},
+		{"NonRetryable", 2, []*resource.RetryError{resource.NonRetryableError(fatalErr)}, fatalErr, 1},
+		{"NoRetries", 0, []*resource.RetryError{resource.RetryableError(retryErr)}, retryErr, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			err := RetryWithBackoff(context.Background(), tc.maxRetries, time.Millisecond, 2*time.Millisecond, func() *resource.RetryError {
+				result := tc.results[calls]
+				calls++
+				return result
+			})
+			if err != tc.expectErr {
+				t.Errorf("bad error: want %v, got %v", tc.expectErr, err)
+			}
+			if calls != tc.expectedCalls {
+				t.Errorf("bad number of calls: want %d, got %d", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryWithBackoffCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- RetryWithBackoff(ctx, 5, time.Minute, time.Minute, func() *resource.RetryError {
+			calls++
+			return resource.RetryableError(errors.New("retry me"))
+		})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("bad error: want %v, got %v", context.Canceled, err)
+		}
+		if calls != 1 {
+			t.Errorf("bad number of calls: want 1, got %d", calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retrying didn't stop when the context was canceled")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	min, max := 100*time.Millisecond, time.Second
+	for attempt := 0; attempt < 10; attempt++ {
+		wait := Backoff(min, max, attempt)
+		if wait < min || wait > max {
+			t.Errorf("attempt %d: backoff %s outside of [%s, %s]", attempt, wait, min, max)
+		}
+	}
+	if wait := Backoff(min, max, 20); wait < 3*max/4 {
+		t.Errorf("expected backoff to reach the maximum, got %s", wait)
+	}
+}
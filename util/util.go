@@ -37,10 +37,6 @@ func ToStringArray(input []interface{}) []string {
 	return output
 }
 
-func Is404(err error) bool {
-	return strings.Contains(err.Error(), "Code: 404")
-}
-
 func CalculateConflictsWith(self string, group []string) []string {
 	if len(group) < 2 {
 		return []string{}
@@ -80,19 +76,6 @@ func JsonStringArrayToStringArray(jsonList []interface{}) []string {
 	return strList
 }
 
-func IsExpiredTokenErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	if strings.Contains(err.Error(), "invalid accessor") {
-		return true
-	}
-	if strings.Contains(err.Error(), "failed to find accessor entry") {
-		return true
-	}
-	return false
-}
-
 func TestCheckResourceAttrJSON(name, key, value string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		resourceState, ok := s.RootModule().Resources[name]
@@ -1,21 +0,0 @@
-package util
-
-import (
-	"fmt"
-	"testing"
-)
-
-func TestExpiredTokenError(t *testing.T) {
-	if ok := IsExpiredTokenErr(fmt.Errorf("error: invalid accessor custom_accesor_value")); !ok {
-		t.Errorf("Should be expired")
-	}
-	if ok := IsExpiredTokenErr(fmt.Errorf("error: failed to find accessor entry custom_accesor_value")); !ok {
-		t.Errorf("Should be expired")
-	}
-	if ok := IsExpiredTokenErr(nil); ok {
-		t.Errorf("Shouldn't be expired")
-	}
-	if ok := IsExpiredTokenErr(fmt.Errorf("Error making request")); ok {
-		t.Errorf("Shouldn't be expired")
-	}
-}
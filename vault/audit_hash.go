@@ -0,0 +1,111 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/consts"
+)
+
+// auditHash returns the HMAC of input computed by the audit device at
+// auditPath, which is how the device would write the value to its log.
+// Audit devices are only enabled in the root namespace.
+func auditHash(client *api.Client, auditPath, input string) (string, error) {
+	path := "sys/audit-hash/" + strings.Trim(auditPath, "/")
+	r := client.NewRequest("POST", "/v1/"+path)
+	r.Headers.Del(consts.NamespaceHeaderName)
+	if err := r.SetJSONBody(map[string]interface{}{"input": input}); err != nil {
+		return "", err
+	}
+
+	resp, err := client.RawRequest(r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return "", fmt.Errorf("error hashing with %q: %s", path, err)
+	}
+
+	var result struct {
+		Hash string `json:"hash"`
+	}
+	if err := resp.DecodeJSON(&result); err != nil {
+		return "", fmt.Errorf("error decoding the response from %q: %s", path, err)
+	}
+	if result.Hash == "" {
+		return "", fmt.Errorf("%q did not return a hash", path)
+	}
+	return result.Hash, nil
+}
+
+// auditHashValues returns a copy of data with each value replaced by its
+// HMAC. Values that aren't strings are hashed as JSON.
+func auditHashValues(client *api.Client, auditPath string, data map[string]interface{}) (map[string]interface{}, error) {
+	hashed := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		input, ok := v.(string)
+		if !ok {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling JSON for %q: %s", k, err)
+			}
+			input = string(b)
+		}
+		hash, err := auditHash(client, auditPath, input)
+		if err != nil {
+			return nil, err
+		}
+		hashed[k] = hash
+	}
+	return hashed, nil
+}
+
+// suppressHashedDataDiff removes the diff between the plaintext data_json
+// of a vault_generic_secret in the configuration and the hashes stored in
+// the state, when hashing the configured values gives the same hashes.
+func suppressHashedDataDiff(meta *ProviderMeta, info *terraform.InstanceInfo, s *terraform.InstanceState, diff *terraform.InstanceDiff) (*terraform.InstanceDiff, error) {
+	if info.Type != "vault_generic_secret" || diff == nil || s == nil || s.ID == "" || diff.RequiresNew() {
+		return diff, nil
+	}
+
+	r := &plannedResource{state: s, diff: diff}
+	auditPath, ok := r.Get("audit_hash_path")
+	if !ok || auditPath == "" {
+		return diff, nil
+	}
+	// Hashes from another audit device, or plaintext stored before hashing
+	// was turned on, can't be compared.
+	if s.Attributes["audit_hash_path"] != auditPath {
+		return diff, nil
+	}
+
+	attr, ok := diff.GetAttribute("data_json")
+	if !ok || attr.NewComputed {
+		return diff, nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(attr.New), &data); err != nil {
+		return nil, fmt.Errorf("data_json %#v syntax error: %s", attr.New, err)
+	}
+	hashed, err := auditHashValues(meta.GetClient(), auditPath, data)
+	if err != nil {
+		return nil, err
+	}
+	hashedJSON, err := json.Marshal(hashed)
+	if err != nil {
+		return nil, err
+	}
+	if string(hashedJSON) != attr.Old {
+		return diff, nil
+	}
+
+	diff.DelAttribute("data_json")
+	if diff.Empty() {
+		return nil, nil
+	}
+	return diff, nil
+}
@@ -0,0 +1,525 @@
+package vault
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	// capabilityBatchDelay is how long a capability check waits for checks
+	// from other resources to join its request to sys/capabilities-self.
+	capabilityBatchDelay = 25 * time.Millisecond
+
+	// capabilityBatchSize is the largest number of paths sent in one
+	// request to sys/capabilities-self.
+	capabilityBatchSize = 100
+)
+
+// capabilityRequirement is a capability that the provider token needs on a
+// path in order to manage a resource.
+type capabilityRequirement struct {
+	Path string
+
+	// Write requires the create or update capability; read is required
+	// otherwise.
+	Write bool
+
+	// Sudo additionally requires the sudo capability.
+	Sudo bool
+}
+
+func (r capabilityRequirement) String() string {
+	required := "read"
+	if r.Write {
+		required = "create|update"
+	}
+	if r.Sudo {
+		required += ", sudo"
+	}
+	return required
+}
+
+// satisfiedBy reports whether the capabilities granted on the path meet the
+// requirement.
+func (r capabilityRequirement) satisfiedBy(granted []string) bool {
+	has := map[string]bool{}
+	for _, capability := range granted {
+		has[capability] = true
+	}
+
+	switch {
+	case has["root"]:
+		return true
+	case has["deny"]:
+		return false
+	case r.Sudo && !has["sudo"]:
+		return false
+	case r.Write:
+		return has["create"] || has["update"]
+	}
+	return has["read"]
+}
+
+// plannedResource gives the capability requirement functions access to the
+// values a resource will have once the plan is applied.
+type plannedResource struct {
+	state *terraform.InstanceState
+	diff  *terraform.InstanceDiff
+}
+
+// Get returns the planned value of an attribute, and false if the value
+// won't be known until apply.
+func (r *plannedResource) Get(key string) (string, bool) {
+	if r.diff != nil {
+		if attr, ok := r.diff.GetAttribute(key); ok {
+			if attr.NewComputed {
+				return "", false
+			}
+			if attr.NewRemoved {
+				return "", true
+			}
+			return attr.New, true
+		}
+	}
+	if r.state != nil {
+		return r.state.Attributes[key], true
+	}
+	return "", true
+}
+
+// IsNew reports whether the resource will be created.
+func (r *plannedResource) IsNew() bool {
+	return r.state == nil || r.state.ID == "" || (r.diff != nil && r.diff.RequiresNew())
+}
+
+// HasChanges reports whether applying the plan writes to the resource.
+func (r *plannedResource) HasChanges() bool {
+	return r.diff != nil && !r.diff.Empty()
+}
+
+// HasChange reports whether the plan changes the given attribute.
+func (r *plannedResource) HasChange(key string) bool {
+	if r.diff == nil {
+		return false
+	}
+	attr, ok := r.diff.GetAttribute(key)
+	return ok && (attr.NewComputed || attr.Old != attr.New)
+}
+
+// capabilityRequirementsFunc returns the capabilities needed to refresh and
+// apply a planned resource. Requirements on paths that depend on values that
+// are not yet known are left out.
+type capabilityRequirementsFunc func(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error)
+
+// capabilityRequirements lists the resources that the capability preflight
+// knows how to check.
+var capabilityRequirements = map[string]capabilityRequirementsFunc{
+	"vault_approle_auth_backend_role":    authBackendObjectCapabilities("backend", "role_name", "role"),
+	"vault_aws_auth_backend_role":        authBackendObjectCapabilities("backend", "role", "role"),
+	"vault_cert_auth_backend_role":       authBackendObjectCapabilities("backend", "name", "certs"),
+	"vault_gcp_auth_backend_role":        authBackendObjectCapabilities("backend", "role", "role"),
+	"vault_jwt_auth_backend_role":        authBackendObjectCapabilities("backend", "role_name", "role"),
+	"vault_kubernetes_auth_backend_role": authBackendObjectCapabilities("backend", "role_name", "role"),
+	"vault_ldap_auth_backend_group":      authBackendObjectCapabilities("backend", "groupname", "groups"),
+	"vault_ldap_auth_backend_user":       authBackendObjectCapabilities("backend", "username", "users"),
+	"vault_token_auth_backend_role":      tokenAuthBackendRoleCapabilities,
+	"vault_aws_secret_backend_role":      secretBackendRoleCapabilities,
+	"vault_database_secret_backend_role": secretBackendRoleCapabilities,
+	"vault_rabbitmq_secret_backend_role": secretBackendRoleCapabilities,
+	"vault_audit":                        auditCapabilities,
+	"vault_auth_backend":                 authBackendCapabilities,
+	"vault_generic_secret":               genericSecretCapabilities,
+	"vault_kv_secret_backend_v2":         kvSecretBackendV2Capabilities,
+	"vault_kv_secret_v2":                 kvSecretV2Capabilities,
+	"vault_kv_secret_v2_metadata":        kvSecretV2MetadataCapabilities,
+	"vault_mount":                        mountCapabilities,
+	"vault_policy":                       policyCapabilities,
+}
+
+// requirePaths returns a read requirement on each of readPaths and, when
+// the plan writes to the resource, a write requirement on each of
+// writePaths. Resources are read back after they are written, so reads are
+// needed even for new resources.
+func requirePaths(r *plannedResource, readPaths, writePaths []string) []capabilityRequirement {
+	var reqs []capabilityRequirement
+	for _, p := range readPaths {
+		reqs = append(reqs, capabilityRequirement{Path: p})
+	}
+	if r.HasChanges() {
+		for _, p := range writePaths {
+			reqs = append(reqs, capabilityRequirement{Path: p, Write: true})
+		}
+	}
+	return reqs
+}
+
+func authBackendObjectCapabilities(backendKey, nameKey, segment string) capabilityRequirementsFunc {
+	return func(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+		backend, ok := r.Get(backendKey)
+		if !ok {
+			return nil, nil
+		}
+		name, ok := r.Get(nameKey)
+		if !ok {
+			return nil, nil
+		}
+		p := "auth/" + strings.Trim(backend, "/") + "/" + segment + "/" + strings.Trim(name, "/")
+		return requirePaths(r, []string{p}, []string{p}), nil
+	}
+}
+
+func tokenAuthBackendRoleCapabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	name, ok := r.Get("role_name")
+	if !ok {
+		return nil, nil
+	}
+	p := tokenAuthBackendRolePath(name)
+	return requirePaths(r, []string{p}, []string{p}), nil
+}
+
+func secretBackendRoleCapabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	backend, ok := r.Get("backend")
+	if !ok {
+		return nil, nil
+	}
+	name, ok := r.Get("name")
+	if !ok {
+		return nil, nil
+	}
+	p := strings.Trim(backend, "/") + "/roles/" + name
+	return requirePaths(r, []string{p}, []string{p}), nil
+}
+
+func policyCapabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	name, ok := r.Get("name")
+	if !ok {
+		return nil, nil
+	}
+	return requirePaths(r, []string{"sys/policies/acl/" + name}, []string{"sys/policy/" + name}), nil
+}
+
+func mountCapabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	p, ok := r.Get("path")
+	if !ok {
+		return nil, nil
+	}
+	p = strings.Trim(p, "/")
+
+	writePaths := []string{"sys/mounts/" + p}
+	if !r.IsNew() {
+		writePaths = []string{"sys/mounts/" + p + "/tune"}
+		if r.HasChange("path") {
+			writePaths = append(writePaths, "sys/remount")
+		}
+	}
+	return requirePaths(r, []string{"sys/mounts"}, writePaths), nil
+}
+
+func authBackendCapabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	p, ok := r.Get("path")
+	if !ok {
+		return nil, nil
+	}
+	if p == "" {
+		if p, ok = r.Get("type"); !ok {
+			return nil, nil
+		}
+	}
+	p = strings.Trim(p, "/")
+
+	reqs := requirePaths(r, []string{"sys/auth"}, nil)
+	if r.HasChanges() {
+		reqs = append(reqs, capabilityRequirement{Path: "sys/auth/" + p, Write: true, Sudo: true})
+	}
+	return reqs, nil
+}
+
+func auditCapabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	p, ok := r.Get("path")
+	if !ok {
+		return nil, nil
+	}
+	if p == "" {
+		if p, ok = r.Get("type"); !ok {
+			return nil, nil
+		}
+	}
+
+	reqs := []capabilityRequirement{{Path: "sys/audit", Sudo: true}}
+	if r.HasChanges() {
+		reqs = append(reqs, capabilityRequirement{Path: "sys/audit/" + strings.Trim(p, "/"), Write: true, Sudo: true})
+	}
+	return reqs, nil
+}
+
+func genericSecretCapabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	p, ok := r.Get("path")
+	if !ok {
+		return nil, nil
+	}
+
+	mountPath, v2, err := isKVv2(p, client, serverVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error determining if it's a v2 path: %s", err)
+	}
+	if v2 {
+		p = addPrefixToVKVPath(p, mountPath, "data")
+	}
+
+	var readPaths []string
+	if disableRead, _ := r.Get("disable_read"); disableRead != "true" {
+		readPaths = []string{p}
+	}
+	return requirePaths(r, readPaths, []string{p}), nil
+}
+
+func kvSecretV2Capabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	mount, ok := r.Get("mount")
+	if !ok {
+		return nil, nil
+	}
+	name, ok := r.Get("name")
+	if !ok {
+		return nil, nil
+	}
+	p := kvSecretV2Path(mount, "data", name)
+	return requirePaths(r, []string{p}, []string{p}), nil
+}
+
+func kvSecretV2MetadataCapabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	mount, ok := r.Get("mount")
+	if !ok {
+		return nil, nil
+	}
+	name, ok := r.Get("name")
+	if !ok {
+		return nil, nil
+	}
+	p := kvSecretV2Path(mount, "metadata", name)
+	return requirePaths(r, []string{p}, []string{p}), nil
+}
+
+func kvSecretBackendV2Capabilities(r *plannedResource, client *api.Client, serverVersion *version.Version) ([]capabilityRequirement, error) {
+	mount, ok := r.Get("mount")
+	if !ok {
+		return nil, nil
+	}
+	p := kvSecretBackendV2ConfigPath(mount)
+	return requirePaths(r, []string{p}, []string{p}), nil
+}
+
+// capabilityChecker looks up the capabilities of the provider token for the
+// paths that resources will touch. Lookups from concurrent diffs are
+// batched into a single request to sys/capabilities-self, and the results
+// are kept for the rest of the run.
+type capabilityChecker struct {
+	delay     time.Duration
+	batchSize int
+
+	lock    sync.Mutex
+	granted map[*api.Client]map[string][]string
+	batches map[*api.Client]*capabilityBatch
+}
+
+type capabilityBatch struct {
+	client *api.Client
+	paths  []string
+	once   sync.Once
+	done   chan struct{}
+	err    error
+}
+
+func newCapabilityChecker() *capabilityChecker {
+	return &capabilityChecker{
+		delay:     capabilityBatchDelay,
+		batchSize: capabilityBatchSize,
+		granted:   map[*api.Client]map[string][]string{},
+		batches:   map[*api.Client]*capabilityBatch{},
+	}
+}
+
+// checkResource fails if the provider token lacks any of the capabilities
+// that the planned resource needs.
+func (c *capabilityChecker) checkResource(meta *ProviderMeta, info *terraform.InstanceInfo, s *terraform.InstanceState, diff *terraform.InstanceDiff) error {
+	requirementsFunc, ok := capabilityRequirements[info.Type]
+	if !ok {
+		return nil
+	}
+
+	r := &plannedResource{state: s, diff: diff}
+	namespace, ok := r.Get("namespace")
+	if !ok {
+		return nil
+	}
+	client, err := meta.namespaceClient(namespace)
+	if err != nil {
+		return err
+	}
+
+	reqs, err := requirementsFunc(r, client, meta.ServerVersion())
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		paths = append(paths, req.Path)
+	}
+	granted, err := c.capabilities(client, paths)
+	if err != nil {
+		return fmt.Errorf("error checking token capabilities: %s", err)
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	missing := 0
+	for _, req := range reqs {
+		if req.satisfiedBy(granted[req.Path]) {
+			continue
+		}
+		if missing == 0 {
+			fmt.Fprintln(w, "  PATH\tREQUIRED\tGRANTED")
+		}
+		missing++
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", req.Path, req, strings.Join(granted[req.Path], ", "))
+	}
+	if missing == 0 {
+		return nil
+	}
+	w.Flush()
+
+	// Terraform prefixes the error with the resource address.
+	return fmt.Errorf("the Vault token is missing capabilities needed for this resource:\n\n%s", buf.String())
+}
+
+// capabilities returns the capabilities of the client's token on each of
+// paths.
+func (c *capabilityChecker) capabilities(client *api.Client, paths []string) (map[string][]string, error) {
+	var waitFor []*capabilityBatch
+
+	c.lock.Lock()
+	granted := c.granted[client]
+	if granted == nil {
+		granted = map[string][]string{}
+		c.granted[client] = granted
+	}
+	for _, p := range paths {
+		if _, ok := granted[p]; ok {
+			continue
+		}
+
+		batch := c.batches[client]
+		if batch == nil {
+			batch = &capabilityBatch{client: client, done: make(chan struct{})}
+			c.batches[client] = batch
+			time.AfterFunc(c.delay, func() { c.flush(batch) })
+		}
+		if !containsString(batch.paths, p) {
+			batch.paths = append(batch.paths, p)
+		}
+		if len(waitFor) == 0 || waitFor[len(waitFor)-1] != batch {
+			waitFor = append(waitFor, batch)
+		}
+		if len(batch.paths) >= c.batchSize {
+			delete(c.batches, client)
+			go c.flush(batch)
+		}
+	}
+	c.lock.Unlock()
+
+	for _, batch := range waitFor {
+		<-batch.done
+		if batch.err != nil {
+			return nil, batch.err
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	result := make(map[string][]string, len(paths))
+	for _, p := range paths {
+		result[p] = granted[p]
+	}
+	return result, nil
+}
+
+func (c *capabilityChecker) flush(batch *capabilityBatch) {
+	batch.once.Do(func() {
+		c.lock.Lock()
+		if c.batches[batch.client] == batch {
+			delete(c.batches, batch.client)
+		}
+		paths := append([]string(nil), batch.paths...)
+		c.lock.Unlock()
+
+		sort.Strings(paths)
+		log.Printf("[DEBUG] Checking token capabilities on %d paths", len(paths))
+		secret, err := batch.client.Logical().Write("sys/capabilities-self", map[string]interface{}{
+			"paths": paths,
+		})
+
+		c.lock.Lock()
+		switch {
+		case err != nil:
+			batch.err = err
+		case secret == nil:
+			batch.err = fmt.Errorf("no response from sys/capabilities-self")
+		default:
+			granted := c.granted[batch.client]
+			for _, p := range paths {
+				granted[p] = capabilitiesFromResponse(secret, p, len(paths))
+			}
+		}
+		c.lock.Unlock()
+
+		close(batch.done)
+	})
+}
+
+// capabilitiesFromResponse returns the capabilities on p reported in a
+// sys/capabilities-self response. Responses list them under each path, and
+// also under "capabilities" when only one path was asked about.
+func capabilitiesFromResponse(secret *api.Secret, p string, count int) []string {
+	raw, ok := secret.Data[p]
+	if !ok {
+		raw, ok = secret.Data[path.Clean(p)]
+	}
+	if !ok && count == 1 {
+		raw = secret.Data["capabilities"]
+	}
+
+	list, _ := raw.([]interface{})
+	capabilities := make([]string, 0, len(list))
+	for _, capability := range list {
+		if s, ok := capability.(string); ok {
+			capabilities = append(capabilities, s)
+		}
+	}
+	if len(capabilities) == 0 {
+		capabilities = []string{"deny"}
+	}
+	return capabilities
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
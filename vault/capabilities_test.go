@@ -0,0 +1,156 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestCapabilityRequirementSatisfiedBy(t *testing.T) {
+	tests := []struct {
+		req     capabilityRequirement
+		granted []string
+		want    bool
+	}{
+		{capabilityRequirement{}, []string{"read"}, true},
+		{capabilityRequirement{}, []string{"list"}, false},
+		{capabilityRequirement{}, []string{"root"}, true},
+		{capabilityRequirement{}, []string{"deny"}, false},
+		{capabilityRequirement{}, nil, false},
+		{capabilityRequirement{Write: true}, []string{"create"}, true},
+		{capabilityRequirement{Write: true}, []string{"update"}, true},
+		{capabilityRequirement{Write: true}, []string{"read", "list"}, false},
+		{capabilityRequirement{Write: true, Sudo: true}, []string{"update"}, false},
+		{capabilityRequirement{Write: true, Sudo: true}, []string{"update", "sudo"}, true},
+		{capabilityRequirement{Write: true, Sudo: true}, []string{"root"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%v", tt.req, tt.granted), func(t *testing.T) {
+			if got := tt.req.satisfiedBy(tt.granted); got != tt.want {
+				t.Errorf("want %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCapabilityCheckerBatches(t *testing.T) {
+	f := newFakeVault(t)
+	f.SetCapabilities("secret/data/b", "read")
+	client := f.Meta().GetClient()
+
+	checker := newCapabilityChecker()
+	paths := []string{"secret/data/a", "secret/data/b", "secret/data/c"}
+
+	var wg sync.WaitGroup
+	results := make([]map[string][]string, len(paths))
+	for i, p := range paths {
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+			granted, err := checker.capabilities(client, []string{p, "sys/mounts"})
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = granted
+		}(i, p)
+	}
+	wg.Wait()
+
+	if got := f.RequestCount("PUT", "sys/capabilities-self"); got != 1 {
+		t.Errorf("expected one request to sys/capabilities-self, got %d", got)
+	}
+	if got := fmt.Sprint(results[1]["secret/data/b"]); got != "[read]" {
+		t.Errorf("bad capabilities for secret/data/b: %s", got)
+	}
+	if got := fmt.Sprint(results[0]["sys/mounts"]); got != "[root]" {
+		t.Errorf("bad capabilities for sys/mounts: %s", got)
+	}
+
+	// Results are reused for the rest of the run.
+	if _, err := checker.capabilities(client, paths); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.RequestCount("PUT", "sys/capabilities-self"); got != 1 {
+		t.Errorf("expected cached capabilities to be used, got %d requests", got)
+	}
+}
+
+func TestCapabilityPreflight_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("secretsv1", "kv", map[string]string{"version": "1"})
+	f.SetCapabilities("sys/policy/*", "read")
+	f.SetCapabilities("secret/data/*", "read")
+
+	config := f.ProviderConfig("check_capabilities = true") + testResourcePolicy_updateConfig + `
+resource "vault_generic_secret" "v1" {
+  path      = "secretsv1/foo"
+  data_json = "{}"
+}
+
+resource "vault_generic_secret" "v2" {
+  path      = "secret/foo"
+  data_json = "{}"
+}
+`
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				ExpectError: regexp.MustCompile(`(?s)` +
+					`vault_policy.test: the Vault token is missing capabilities needed for this resource:.*` +
+					`PATH\s+REQUIRED\s+GRANTED.*sys/policy/dev-team\s+create\|update\s+read`),
+			},
+			{
+				PreConfig: func() {
+					if got := f.RequestCount("PUT", "sys/policy/dev-team"); got != 0 {
+						t.Fatalf("expected nothing to be written, got %d writes", got)
+					}
+				},
+				Config: config,
+				ExpectError: regexp.MustCompile(`(?s)vault_generic_secret.v2: .*` +
+					`secret/data/foo\s+create\|update\s+read`),
+			},
+			{
+				PreConfig: func() {
+					f.SetCapabilities("sys/policy/*", "create", "update")
+					f.SetCapabilities("secret/data/*", "read", "update")
+				},
+				Config: config,
+				Check: func(s *terraform.State) error {
+					if _, ok := f.Policy("dev-team"); !ok {
+						return fmt.Errorf("policy was not written")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestCapabilityPreflight_disabled(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourcePolicy_updateConfig,
+				Check: func(s *terraform.State) error {
+					for _, req := range f.Requests() {
+						if strings.HasPrefix(req.Path, "sys/capabilities-self") {
+							return fmt.Errorf("capabilities were checked without check_capabilities")
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -1,6 +1,7 @@
 package vault
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -14,10 +15,13 @@ import (
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
-
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
+// awsCredentialsMaxRetries bounds each check of new credentials to about a
+// minute of backing off.
+const awsCredentialsMaxRetries = 9
+
 func awsAccessCredentialsDataSource() *schema.Resource {
 	return &schema.Resource{
 		Read: awsAccessCredentialsDataSourceRead,
@@ -92,7 +96,10 @@ func awsAccessCredentialsDataSource() *schema.Resource {
 }
 
 func awsAccessCredentialsDataSourceRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	credType := d.Get("type").(string)
@@ -138,8 +145,10 @@ func awsAccessCredentialsDataSourceRead(d *schema.ResourceData, meta interface{}
 	iamconn := iam.New(sess)
 	stsconn := sts.New(sess)
 
+	// New credentials take a while to be accepted everywhere by AWS, so
+	// they are checked until they work three times.
 	for successes := 0; successes < 3; successes++ {
-		err = resource.Retry(1*time.Minute, func() *resource.RetryError {
+		err = util.RetryWithBackoff(context.Background(), awsCredentialsMaxRetries, time.Second, 10*time.Second, func() *resource.RetryError {
 			if credType == "creds" {
 				log.Printf("[DEBUG] Checking if AWS creds %q are valid", secret.LeaseID)
 				_, err := iamconn.GetUser(nil)
@@ -7,8 +7,6 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
-
-	"github.com/hashicorp/vault/api"
 )
 
 func genericSecretDataSource() *schema.Resource {
@@ -69,14 +67,17 @@ func genericSecretDataSource() *schema.Resource {
 }
 
 func genericSecretDataSourceRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Get("path").(string)
 
 	secretVersion := d.Get("version").(int)
 	log.Printf("[DEBUG] Reading %s %d from Vault", path, secretVersion)
 
-	secret, err := versionedSecret(secretVersion, path, client)
+	secret, err := versionedSecret(secretVersion, path, client, meta.(*ProviderMeta).ServerVersion())
 	if err != nil {
 		return fmt.Errorf("error reading from Vault: %s", err)
 	}
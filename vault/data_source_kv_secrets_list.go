@@ -0,0 +1,107 @@
+package vault
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func kvSecretsListDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: kvSecretsListDataSourceRead,
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Full path, including the mount, under which secrets will be listed.",
+			},
+
+			"recursive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "List the contents of folders as well.",
+			},
+
+			"max_depth": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of levels of folders to list when recursive, where 1 only lists path itself. There's no limit when set to 0.",
+			},
+
+			"keys": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Paths of the secrets and folders, relative to path. Folders end with a slash.",
+			},
+
+			"secrets": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Paths of the secrets, relative to path.",
+			},
+
+			"folders": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Paths of the folders, relative to path, without a trailing slash.",
+			},
+		},
+	}
+}
+
+func kvSecretsListDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := strings.Trim(d.Get("path").(string), "/")
+	mountPath, v2, err := isKVv2(path, client, meta.(*ProviderMeta).ServerVersion())
+	if err != nil {
+		return fmt.Errorf("error determining if it's a v2 path: %s", err)
+	}
+
+	maxDepth := 1
+	if d.Get("recursive").(bool) {
+		maxDepth = d.Get("max_depth").(int)
+	}
+
+	keys, err := kvListRecursive(client, path, mountPath, v2, maxDepth)
+	if err != nil {
+		return err
+	}
+	sort.Strings(keys)
+
+	secrets := []string{}
+	folders := []string{}
+	for _, k := range keys {
+		if strings.HasSuffix(k, "/") {
+			folders = append(folders, strings.TrimSuffix(k, "/"))
+		} else {
+			secrets = append(secrets, k)
+		}
+	}
+
+	d.SetId(path)
+	if err := d.Set("keys", keys); err != nil {
+		return err
+	}
+	if err := d.Set("secrets", secrets); err != nil {
+		return err
+	}
+	if err := d.Set("folders", folders); err != nil {
+		return err
+	}
+
+	return nil
+}
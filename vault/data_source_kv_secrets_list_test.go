@@ -0,0 +1,154 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceKVSecretsList(t *testing.T) {
+	mount := acctest.RandomWithPrefix("tf-test-kv")
+	resource.Test(t, resource.TestCase{
+		Providers: testProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testResourceKVSecretV2_mountConfig(mount) + `
+resource "vault_generic_secret" "a" {
+  path      = "${vault_mount.kv.path}/apps/a"
+  data_json = "{\"zip\": \"zap\"}"
+}
+
+resource "vault_generic_secret" "b" {
+  path      = "${vault_mount.kv.path}/apps/team/b"
+  data_json = "{\"zip\": \"zap\"}"
+}
+
+data "vault_kv_secrets_list" "test" {
+  path      = "${vault_mount.kv.path}/apps"
+  recursive = true
+
+  depends_on = ["vault_generic_secret.a", "vault_generic_secret.b"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.#", "3"),
+					resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.0", "a"),
+					resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.1", "team/"),
+					resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.2", "team/b"),
+				),
+			},
+		},
+	})
+}
+
+func testDataSourceKVSecretsList_config(path string, recursive bool, maxDepth int) string {
+	return fmt.Sprintf(`
+data "vault_kv_secrets_list" "test" {
+  path      = %q
+  recursive = %t
+  max_depth = %d
+}
+`, path, recursive, maxDepth)
+}
+
+func testDataSourceKVSecretsList_fakeVaultWrite(f *fakeVault, mount string, v2 bool) {
+	for _, name := range []string{"apps/a", "apps/b", "apps/team/c", "apps/team/nested/d", "apps/team", "other"} {
+		if v2 {
+			f.Write(mount+"/data/"+name, map[string]interface{}{
+				"data": map[string]interface{}{"zip": "zap"},
+			})
+		} else {
+			f.Write(mount+"/"+name, map[string]interface{}{"zip": "zap"})
+		}
+	}
+}
+
+func TestDataSourceKVSecretsList_fakeVault(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		t.Run(fmt.Sprintf("v2=%t", v2), func(t *testing.T) {
+			f := newFakeVault(t)
+			mount := "secret"
+			if !v2 {
+				mount = "kv"
+				f.Mount(mount, "kv", nil)
+			}
+			testDataSourceKVSecretsList_fakeVaultWrite(f, mount, v2)
+
+			resource.UnitTest(t, resource.TestCase{
+				Providers: f.Providers(),
+				Steps: []resource.TestStep{
+					{
+						Config: f.ProviderConfig() + testDataSourceKVSecretsList_config(mount+"/apps", false, 0),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.#", "4"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.0", "a"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.1", "b"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.2", "team"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.3", "team/"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.#", "3"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.2", "team"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "folders.#", "1"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "folders.0", "team"),
+						),
+					},
+					{
+						Config: f.ProviderConfig() + testDataSourceKVSecretsList_config(mount+"/apps", true, 0),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.#", "7"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.#", "5"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.3", "team/c"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.4", "team/nested/d"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "folders.#", "2"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "folders.1", "team/nested"),
+						),
+					},
+					{
+						Config: f.ProviderConfig() + testDataSourceKVSecretsList_config(mount+"/apps", true, 2),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.#", "4"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "folders.#", "2"),
+						),
+					},
+					{
+						// The mount itself.
+						Config: f.ProviderConfig() + testDataSourceKVSecretsList_config(mount, false, 0),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.#", "1"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.0", "other"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "folders.#", "1"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "folders.0", "apps"),
+						),
+					},
+					{
+						Config: f.ProviderConfig() + testDataSourceKVSecretsList_config(mount+"/missing", true, 0),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "keys.#", "0"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "secrets.#", "0"),
+							resource.TestCheckResourceAttr("data.vault_kv_secrets_list.test", "folders.#", "0"),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestDataSourceKVSecretsList_fakeVaultError(t *testing.T) {
+	f := newFakeVault(t)
+	testDataSourceKVSecretsList_fakeVaultWrite(f, "secret", true)
+	f.InjectFault(fakeVaultFault{Method: "LIST", Path: "secret/metadata/apps/team", Status: 403})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config:      f.ProviderConfig() + testDataSourceKVSecretsList_config("secret/apps", true, 0),
+				ExpectError: regexp.MustCompile(`error listing "secret/metadata/apps/team"`),
+			},
+		},
+	})
+}
@@ -0,0 +1,108 @@
+package vault
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/vault/api"
+)
+
+func serverInfoDataSource() *schema.Resource {
+	return &schema.Resource{
+		Read: serverInfoDataSourceRead,
+
+		Schema: map[string]*schema.Schema{
+			"version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Version of the Vault server.",
+			},
+
+			"cluster_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the Vault cluster.",
+			},
+
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Unique identifier of the Vault cluster.",
+			},
+
+			"sealed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the server is sealed.",
+			},
+
+			"ha_enabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the server is part of a highly available cluster.",
+			},
+
+			"standby": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the server is a standby node of a highly available cluster.",
+			},
+
+			"leader_address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Address of the active node of a highly available cluster.",
+			},
+		},
+	}
+}
+
+func serverInfoDataSourceRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Reading Vault server information")
+
+	// Ask for a 200 whatever the state of the node, so that a standby or
+	// sealed server doesn't turn into an error.
+	params := map[string]string{
+		"standbycode":            "200",
+		"performancestandbycode": "200",
+		"drsecondarycode":        "200",
+		"sealedcode":             "200",
+		"uninitcode":             "200",
+	}
+	// sys/health and sys/leader describe the whole server, and are only
+	// served in the root namespace.
+	var health api.HealthResponse
+	if err := rootSysRequest(client, "sys/health", params, &health); err != nil {
+		return fmt.Errorf("error reading Vault server health: %s", err)
+	}
+
+	var leader api.LeaderResponse
+	if health.Sealed {
+		// A sealed server can't tell which node is active.
+		log.Printf("[WARN] Vault is sealed; not reading its HA status")
+	} else if err := rootSysRequest(client, "sys/leader", nil, &leader); err != nil {
+		return fmt.Errorf("error reading Vault HA status: %s", err)
+	}
+
+	id := health.ClusterID
+	if id == "" {
+		id = client.Address()
+	}
+	d.SetId(id)
+
+	d.Set("version", health.Version)
+	d.Set("cluster_name", health.ClusterName)
+	d.Set("cluster_id", health.ClusterID)
+	d.Set("sealed", health.Sealed)
+	d.Set("ha_enabled", leader.HAEnabled)
+	d.Set("standby", health.Standby)
+	d.Set("leader_address", leader.LeaderAddress)
+
+	return nil
+}
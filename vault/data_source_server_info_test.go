@@ -0,0 +1,80 @@
+package vault
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+const testDataSourceServerInfo_config = `
+data "vault_server_info" "test" {}
+`
+
+func TestDataSourceServerInfo_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testDataSourceServerInfo_config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "version", "0.11.1"),
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "cluster_name", "vault-cluster-fake"),
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "cluster_id", "00000000-0000-0000-0000-000000000000"),
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "sealed", "false"),
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "ha_enabled", "false"),
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "standby", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceServerInfo_fakeVaultStandby(t *testing.T) {
+	f := newFakeVault(t)
+	f.SetVersion("1.0.0+ent")
+	f.SetStandby(true)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testDataSourceServerInfo_config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "version", "1.0.0+ent"),
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "ha_enabled", "true"),
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "standby", "true"),
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "leader_address", "https://vault-active.fake:8200"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceServerInfo_fakeVaultNamespace(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+data "vault_server_info" "test" {
+  namespace = "team"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vault_server_info.test", "version", "0.11.1"),
+					func(*terraform.State) error {
+						for _, req := range f.Requests() {
+							if req.Path == "sys/health" && req.Namespace != "" {
+								return fmt.Errorf("sys/health was read in namespace %q", req.Namespace)
+							}
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
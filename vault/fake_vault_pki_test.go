@@ -0,0 +1,647 @@
+package vault
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/helper/certutil"
+)
+
+// fakeVaultPKIDefaultTTL is the validity of certificates issued without a
+// TTL, which is the system's default maximum lease TTL.
+const fakeVaultPKIDefaultTTL = 768 * time.Hour
+
+// fakeVaultPKI is the state of a pki secrets engine. Like Vault, it keeps
+// the key of an intermediate CA without a certificate until the signed
+// certificate is set.
+type fakeVaultPKI struct {
+	CA    *x509.Certificate
+	CAPEM string
+	CAKey crypto.Signer
+	Roles map[string]map[string]interface{}
+	Certs map[string]*fakeVaultPKICert
+}
+
+// fakeVaultPKICert is a certificate issued by a pki secrets engine.
+type fakeVaultPKICert struct {
+	PEM            string
+	RevocationTime int64
+}
+
+// fakeVaultPKIRoleDefaults are the role parameters that the fake supports,
+// with the values that Vault sets when they are omitted.
+var fakeVaultPKIRoleDefaults = map[string]interface{}{
+	"ttl":                   0,
+	"max_ttl":               0,
+	"allow_localhost":       true,
+	"allowed_domains":       []string{},
+	"allow_bare_domains":    false,
+	"allow_subdomains":      false,
+	"allow_glob_domains":    false,
+	"allow_any_name":        false,
+	"enforce_hostnames":     true,
+	"allow_ip_sans":         true,
+	"server_flag":           true,
+	"client_flag":           true,
+	"code_signing_flag":     false,
+	"email_protection_flag": false,
+	"key_type":              "rsa",
+	"key_bits":              2048,
+	"key_usage":             []string{"DigitalSignature", "KeyAgreement", "KeyEncipherment"},
+	"ext_key_usage":         []string{},
+	"use_csr_common_name":   true,
+	"use_csr_sans":          true,
+	"ou":                    []string{},
+	"organization":          []string{},
+	"generate_lease":        false,
+	"no_store":              false,
+	"require_cn":            true,
+}
+
+// pki serves the endpoints of a pki secrets engine that the provider uses.
+func (f *fakeVault) pki(method string, mount *fakeVaultMount, path string, body map[string]interface{}) (int, interface{}) {
+	if mount.PKI == nil {
+		mount.PKI = &fakeVaultPKI{}
+	}
+	pki := mount.PKI
+
+	switch {
+	case path == "root/generate/internal" || path == "root/generate/exported":
+		if method != "POST" && method != "PUT" {
+			break
+		}
+		return f.pkiGenerateRoot(pki, strings.TrimPrefix(path, "root/generate/") == "exported", body)
+	case path == "intermediate/generate/internal" || path == "intermediate/generate/exported":
+		if method != "POST" && method != "PUT" {
+			break
+		}
+		return f.pkiGenerateIntermediate(pki, strings.TrimPrefix(path, "intermediate/generate/") == "exported", body)
+	case path == "root/sign-intermediate":
+		if method != "POST" && method != "PUT" {
+			break
+		}
+		return f.pkiSignIntermediate(pki, body)
+	case path == "intermediate/set-signed":
+		if method != "POST" && method != "PUT" {
+			break
+		}
+		return f.pkiSetSigned(pki, body)
+	case path == "root":
+		if method != "DELETE" {
+			break
+		}
+		pki.CA, pki.CAPEM, pki.CAKey = nil, "", nil
+		return http.StatusNoContent, nil
+	case path == "roles":
+		if method != "LIST" {
+			break
+		}
+		if len(pki.Roles) == 0 {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		keys := make([]string, 0, len(pki.Roles))
+		for name := range pki.Roles {
+			keys = append(keys, name)
+		}
+		sort.Strings(keys)
+		return http.StatusOK, fakeVaultData(map[string]interface{}{"keys": keys})
+	case strings.HasPrefix(path, "roles/"):
+		name := strings.TrimPrefix(path, "roles/")
+		switch method {
+		case "GET":
+			role, ok := pki.Roles[name]
+			if !ok {
+				return http.StatusNotFound, fakeVaultErrors()
+			}
+			return http.StatusOK, fakeVaultData(role)
+		case "POST", "PUT":
+			return f.pkiWriteRole(pki, name, body)
+		case "DELETE":
+			delete(pki.Roles, name)
+			return http.StatusNoContent, nil
+		}
+	case path == "cert/ca":
+		if method != "GET" {
+			break
+		}
+		if pki.CA == nil {
+			return http.StatusNoContent, nil
+		}
+		return http.StatusOK, fakeVaultData(map[string]interface{}{"certificate": pki.CAPEM})
+	case strings.HasPrefix(path, "cert/"):
+		if method != "GET" {
+			break
+		}
+		cert, ok := pki.Certs[strings.Replace(strings.TrimPrefix(path, "cert/"), "-", ":", -1)]
+		if !ok {
+			return http.StatusNoContent, nil
+		}
+		return http.StatusOK, fakeVaultData(map[string]interface{}{
+			"certificate":     cert.PEM,
+			"revocation_time": cert.RevocationTime,
+		})
+	case strings.HasPrefix(path, "issue/"):
+		if method != "POST" && method != "PUT" {
+			break
+		}
+		return f.pkiIssue(pki, strings.TrimPrefix(path, "issue/"), body)
+	case strings.HasPrefix(path, "sign/"):
+		if method != "POST" && method != "PUT" {
+			break
+		}
+		return f.pkiSign(pki, strings.TrimPrefix(path, "sign/"), body)
+	case path == "revoke":
+		if method != "POST" && method != "PUT" {
+			break
+		}
+		return f.pkiRevoke(pki, body)
+	}
+
+	return fakeVaultNoHandler(path)
+}
+
+func (f *fakeVault) pkiGenerateRoot(pki *fakeVaultPKI, exported bool, body map[string]interface{}) (int, interface{}) {
+	commonName := fakeVaultString(body["common_name"])
+	if commonName == "" {
+		return http.StatusBadRequest, fakeVaultErrors("the common_name field is required")
+	}
+	key, keyType, err := fakeVaultPKIKey(body)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors(err.Error())
+	}
+
+	template, err := fakeVaultPKITemplate(body)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors(err.Error())
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	template.PermittedDNSDomains = fakeVaultStringSlice(body["permitted_dns_domains"])
+	template.MaxPathLen = -1
+	if v, ok := body["max_path_length"]; ok {
+		template.MaxPathLen = int(fakeVaultNumber(v))
+		template.MaxPathLenZero = template.MaxPathLen == 0
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return http.StatusInternalServerError, fakeVaultErrors(err.Error())
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return http.StatusInternalServerError, fakeVaultErrors(err.Error())
+	}
+
+	pki.CA = cert
+	pki.CAPEM = fakeVaultPEM("CERTIFICATE", der)
+	pki.CAKey = key
+
+	data := map[string]interface{}{
+		"certificate":   pki.CAPEM,
+		"issuing_ca":    pki.CAPEM,
+		"serial_number": certutil.GetHexFormatted(cert.SerialNumber.Bytes(), ":"),
+		"expiration":    cert.NotAfter.Unix(),
+	}
+	if exported {
+		data["private_key"], data["private_key_type"] = fakeVaultPrivateKeyPEM(key), keyType
+	}
+	return http.StatusOK, fakeVaultData(data)
+}
+
+func (f *fakeVault) pkiGenerateIntermediate(pki *fakeVaultPKI, exported bool, body map[string]interface{}) (int, interface{}) {
+	commonName := fakeVaultString(body["common_name"])
+	if commonName == "" {
+		return http.StatusBadRequest, fakeVaultErrors("the common_name field is required")
+	}
+	key, keyType, err := fakeVaultPKIKey(body)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors(err.Error())
+	}
+	template, err := fakeVaultPKITemplate(body)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors(err.Error())
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:     template.Subject,
+		DNSNames:    template.DNSNames,
+		IPAddresses: template.IPAddresses,
+	}, key)
+	if err != nil {
+		return http.StatusInternalServerError, fakeVaultErrors(err.Error())
+	}
+
+	pki.CA, pki.CAPEM, pki.CAKey = nil, "", key
+
+	data := map[string]interface{}{
+		"csr": fakeVaultPEM("CERTIFICATE REQUEST", der),
+	}
+	if exported {
+		data["private_key"], data["private_key_type"] = fakeVaultPrivateKeyPEM(key), keyType
+	}
+	return http.StatusOK, fakeVaultData(data)
+}
+
+func (f *fakeVault) pkiSignIntermediate(pki *fakeVaultPKI, body map[string]interface{}) (int, interface{}) {
+	if pki.CA == nil {
+		return http.StatusBadRequest, fakeVaultErrors("unable to fetch local CA certificate and key")
+	}
+	block, _ := pem.Decode([]byte(fakeVaultString(body["csr"])))
+	if block == nil {
+		return http.StatusBadRequest, fakeVaultErrors("csr contains no data")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors("certificate request could not be parsed: " + err.Error())
+	}
+	if body["use_csr_values"] == true {
+		body = fakeVaultCopy(body)
+		body["common_name"] = csr.Subject.CommonName
+	}
+	if fakeVaultString(body["common_name"]) == "" {
+		return http.StatusBadRequest, fakeVaultErrors("the common_name field is required")
+	}
+
+	template, err := fakeVaultPKITemplate(body)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors(err.Error())
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	template.PermittedDNSDomains = fakeVaultStringSlice(body["permitted_dns_domains"])
+	template.MaxPathLen = -1
+	if v, ok := body["max_path_length"]; ok {
+		template.MaxPathLen = int(fakeVaultNumber(v))
+		template.MaxPathLenZero = template.MaxPathLen == 0
+	}
+	if template.NotAfter.After(pki.CA.NotAfter) {
+		template.NotAfter = pki.CA.NotAfter
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, pki.CA, csr.PublicKey, pki.CAKey)
+	if err != nil {
+		return http.StatusInternalServerError, fakeVaultErrors(err.Error())
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return http.StatusInternalServerError, fakeVaultErrors(err.Error())
+	}
+
+	return http.StatusOK, fakeVaultData(map[string]interface{}{
+		"certificate":   fakeVaultPEM("CERTIFICATE", der),
+		"issuing_ca":    pki.CAPEM,
+		"serial_number": certutil.GetHexFormatted(cert.SerialNumber.Bytes(), ":"),
+		"expiration":    cert.NotAfter.Unix(),
+	})
+}
+
+func (f *fakeVault) pkiSetSigned(pki *fakeVaultPKI, body map[string]interface{}) (int, interface{}) {
+	block, _ := pem.Decode([]byte(fakeVaultString(body["certificate"])))
+	if block == nil {
+		return http.StatusBadRequest, fakeVaultErrors("no certificate provided in the \"certificate\" parameter")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors(err.Error())
+	}
+	if pki.CAKey == nil {
+		return http.StatusBadRequest, fakeVaultErrors("could not find an existing private key")
+	}
+	if equal, err := certutil.ComparePublicKeys(pki.CAKey.Public(), cert.PublicKey); err != nil || !equal {
+		return http.StatusBadRequest, fakeVaultErrors("public key of certificate does not match private key")
+	}
+	if !cert.IsCA {
+		return http.StatusBadRequest, fakeVaultErrors("the given certificate is not marked for CA use and cannot be used with this backend")
+	}
+
+	pki.CA = cert
+	pki.CAPEM = fakeVaultPEM("CERTIFICATE", block.Bytes)
+	return http.StatusNoContent, nil
+}
+
+// pkiWriteRole replaces a role, setting omitted parameters to their
+// defaults like Vault does.
+func (f *fakeVault) pkiWriteRole(pki *fakeVaultPKI, name string, body map[string]interface{}) (int, interface{}) {
+	role := make(map[string]interface{}, len(fakeVaultPKIRoleDefaults))
+	for k, def := range fakeVaultPKIRoleDefaults {
+		v, ok := body[k]
+		if !ok {
+			role[k] = def
+			continue
+		}
+		switch def.(type) {
+		case bool:
+			role[k] = v == true || v == "true"
+		case int:
+			role[k] = fakeVaultSeconds(v)
+		case []string:
+			role[k] = fakeVaultStringSlice(v)
+		case string:
+			role[k] = fakeVaultString(v)
+		}
+	}
+
+	switch bits := role["key_bits"].(int); role["key_type"] {
+	case "rsa":
+		if bits < 2048 {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("RSA keys < 2048 bits are unsafe and not supported: got %d", bits))
+		}
+	case "ec":
+		if bits != 224 && bits != 256 && bits != 384 && bits != 521 {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("unsupported bit length for EC key: %d", bits))
+		}
+	case "any":
+	default:
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("unknown key type %s", role["key_type"]))
+	}
+	if maxTTL := role["max_ttl"].(int); maxTTL > 0 && role["ttl"].(int) > maxTTL {
+		return http.StatusBadRequest, fakeVaultErrors(`"ttl" value must be less than "max_ttl" value`)
+	}
+
+	if pki.Roles == nil {
+		pki.Roles = map[string]map[string]interface{}{}
+	}
+	pki.Roles[name] = role
+	return http.StatusNoContent, nil
+}
+
+func (f *fakeVault) pkiIssue(pki *fakeVaultPKI, roleName string, body map[string]interface{}) (int, interface{}) {
+	role, ok := pki.Roles[roleName]
+	if !ok {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("unknown role: %s", roleName))
+	}
+	if pki.CA == nil {
+		return http.StatusBadRequest, fakeVaultErrors("unable to fetch local CA certificate and key")
+	}
+	keyType, keyBits := role["key_type"], role["key_bits"]
+	if keyType == "any" {
+		keyType, keyBits = "rsa", 2048
+	}
+	key, _, err := fakeVaultPKIKey(map[string]interface{}{"key_type": keyType, "key_bits": keyBits})
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors(err.Error())
+	}
+	return f.pkiIssueCertificate(pki, role, body, key.Public(), key)
+}
+
+func (f *fakeVault) pkiSign(pki *fakeVaultPKI, roleName string, body map[string]interface{}) (int, interface{}) {
+	role, ok := pki.Roles[roleName]
+	if !ok {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("unknown role: %s", roleName))
+	}
+	if pki.CA == nil {
+		return http.StatusBadRequest, fakeVaultErrors("unable to fetch local CA certificate and key")
+	}
+	block, _ := pem.Decode([]byte(fakeVaultString(body["csr"])))
+	if block == nil {
+		return http.StatusBadRequest, fakeVaultErrors("csr contains no data")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors("certificate request could not be parsed: " + err.Error())
+	}
+
+	var keyType string
+	switch csr.PublicKey.(type) {
+	case *rsa.PublicKey:
+		keyType = "rsa"
+	case *ecdsa.PublicKey:
+		keyType = "ec"
+	}
+	if role["key_type"] != "any" && role["key_type"] != keyType {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("role requires keys of type %s", role["key_type"]))
+	}
+
+	params := fakeVaultCopy(body)
+	if role["use_csr_common_name"] == true {
+		params["common_name"] = csr.Subject.CommonName
+	}
+	if role["use_csr_sans"] == true {
+		var ips []string
+		for _, ip := range csr.IPAddresses {
+			ips = append(ips, ip.String())
+		}
+		params["alt_names"], params["ip_sans"] = strings.Join(csr.DNSNames, ","), strings.Join(ips, ",")
+	}
+	return f.pkiIssueCertificate(pki, role, params, csr.PublicKey, nil)
+}
+
+// pkiIssueCertificate issues a certificate for the public key, as the role
+// allows, and returns it along with the private key when it's given.
+func (f *fakeVault) pkiIssueCertificate(pki *fakeVaultPKI, role map[string]interface{}, body map[string]interface{}, pub crypto.PublicKey, key crypto.Signer) (int, interface{}) {
+	commonName := fakeVaultString(body["common_name"])
+	if commonName == "" && role["require_cn"] == true {
+		return http.StatusBadRequest, fakeVaultErrors("the common_name field is required, or must be provided in a CSR with \"use_csr_common_name\" set to true, unless \"require_cn\" is set to false")
+	}
+	if commonName != "" && !fakeVaultPKIRoleAllows(role, commonName) {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("common name %s not allowed by this role", commonName))
+	}
+	for _, name := range fakeVaultStringSlice(body["alt_names"]) {
+		if !fakeVaultPKIRoleAllows(role, name) {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("subject alternate name %s not allowed by this role", name))
+		}
+	}
+	if ips := fakeVaultStringSlice(body["ip_sans"]); len(ips) > 0 && role["allow_ip_sans"] != true {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("IP Subject Alternative Names are not allowed in this role, but was provided %s", strings.Join(ips, ",")))
+	}
+
+	params := fakeVaultCopy(body)
+	params["ou"], params["organization"] = role["ou"], role["organization"]
+	if fakeVaultSeconds(params["ttl"]) == 0 {
+		params["ttl"] = role["ttl"]
+	}
+	if maxTTL := role["max_ttl"].(int); maxTTL > 0 && fakeVaultSeconds(params["ttl"]) > maxTTL {
+		params["ttl"] = maxTTL
+	}
+	template, err := fakeVaultPKITemplate(params)
+	if err != nil {
+		return http.StatusBadRequest, fakeVaultErrors(err.Error())
+	}
+	if template.NotAfter.After(pki.CA.NotAfter) {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("cannot satisfy request, as TTL would result in notAfter %s that is beyond the expiration of the CA certificate at %s", template.NotAfter.Format(time.RFC3339Nano), pki.CA.NotAfter.Format(time.RFC3339Nano)))
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	if role["server_flag"] == true {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+	}
+	if role["client_flag"] == true {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, pki.CA, pub, pki.CAKey)
+	if err != nil {
+		return http.StatusInternalServerError, fakeVaultErrors(err.Error())
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return http.StatusInternalServerError, fakeVaultErrors(err.Error())
+	}
+
+	serial := certutil.GetHexFormatted(cert.SerialNumber.Bytes(), ":")
+	certPEM := fakeVaultPEM("CERTIFICATE", der)
+	if role["no_store"] != true {
+		if pki.Certs == nil {
+			pki.Certs = map[string]*fakeVaultPKICert{}
+		}
+		pki.Certs[serial] = &fakeVaultPKICert{PEM: certPEM}
+	}
+
+	data := map[string]interface{}{
+		"certificate":   certPEM,
+		"issuing_ca":    pki.CAPEM,
+		"ca_chain":      []string{pki.CAPEM},
+		"serial_number": serial,
+		"expiration":    cert.NotAfter.Unix(),
+	}
+	if key != nil {
+		data["private_key"] = fakeVaultPrivateKeyPEM(key)
+		data["private_key_type"] = role["key_type"]
+		if data["private_key_type"] == "any" {
+			data["private_key_type"] = "rsa"
+		}
+	}
+	return http.StatusOK, fakeVaultData(data)
+}
+
+func (f *fakeVault) pkiRevoke(pki *fakeVaultPKI, body map[string]interface{}) (int, interface{}) {
+	serial := strings.Replace(fakeVaultString(body["serial_number"]), "-", ":", -1)
+	cert, ok := pki.Certs[serial]
+	if !ok {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("certificate with serial %s not found", serial))
+	}
+	if cert.RevocationTime == 0 {
+		cert.RevocationTime = time.Now().Unix()
+	}
+	return http.StatusOK, fakeVaultData(map[string]interface{}{"revocation_time": cert.RevocationTime})
+}
+
+// fakeVaultPKIRoleAllows reports whether the role allows a name, as a
+// common name or DNS SAN.
+func fakeVaultPKIRoleAllows(role map[string]interface{}, name string) bool {
+	if role["allow_any_name"] == true || (role["allow_localhost"] == true && name == "localhost") {
+		return true
+	}
+	for _, domain := range fakeVaultStringSlice(role["allowed_domains"]) {
+		switch {
+		case role["allow_bare_domains"] == true && name == domain:
+			return true
+		case role["allow_subdomains"] == true && strings.HasSuffix(name, "."+domain):
+			return true
+		case role["allow_glob_domains"] == true && strings.Contains(domain, "*"):
+			if ok, _ := filepath.Match(domain, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fakeVaultPKIKey generates a key as set by the key_type and key_bits
+// parameters.
+func fakeVaultPKIKey(body map[string]interface{}) (crypto.Signer, string, error) {
+	keyType := fakeVaultString(body["key_type"])
+	if keyType == "" {
+		keyType = "rsa"
+	}
+	bits := int(fakeVaultNumber(body["key_bits"]))
+
+	switch keyType {
+	case "rsa":
+		if bits == 0 {
+			bits = 2048
+		}
+		if bits < 2048 {
+			return nil, "", fmt.Errorf("RSA keys < 2048 bits are unsafe and not supported: got %d", bits)
+		}
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		return key, keyType, err
+	case "ec":
+		var curve elliptic.Curve
+		switch bits {
+		case 224:
+			curve = elliptic.P224()
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, "", fmt.Errorf("unsupported bit length for EC key: %d", bits)
+		}
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		return key, keyType, err
+	}
+	return nil, "", fmt.Errorf("unknown key type %s", keyType)
+}
+
+// fakeVaultPKITemplate returns a certificate template with the subject,
+// SANs and validity set by the parameters common to every endpoint that
+// issues certificates.
+func fakeVaultPKITemplate(body map[string]interface{}) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 159))
+	if err != nil {
+		return nil, err
+	}
+
+	commonName := fakeVaultString(body["common_name"])
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:         commonName,
+			OrganizationalUnit: fakeVaultStringSlice(body["ou"]),
+			Organization:       fakeVaultStringSlice(body["organization"]),
+		},
+		NotBefore: time.Now().Add(-30 * time.Second),
+	}
+
+	ttl := time.Duration(fakeVaultSeconds(body["ttl"])) * time.Second
+	if ttl == 0 {
+		ttl = fakeVaultPKIDefaultTTL
+	}
+	template.NotAfter = time.Now().Add(ttl)
+
+	if commonName != "" && body["exclude_cn_from_sans"] != true && !strings.Contains(commonName, " ") {
+		template.DNSNames = append(template.DNSNames, commonName)
+	}
+	template.DNSNames = append(template.DNSNames, fakeVaultStringSlice(body["alt_names"])...)
+	for _, s := range fakeVaultStringSlice(body["ip_sans"]) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("the value %q is not a valid IP address", s)
+		}
+		template.IPAddresses = append(template.IPAddresses, ip)
+	}
+	return template, nil
+}
+
+func fakeVaultPEM(blockType string, der []byte) string {
+	return strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})))
+}
+
+func fakeVaultPrivateKeyPEM(key crypto.Signer) string {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return fakeVaultPEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	case *ecdsa.PrivateKey:
+		der, _ := x509.MarshalECPrivateKey(key)
+		return fakeVaultPEM("EC PRIVATE KEY", der)
+	}
+	return ""
+}
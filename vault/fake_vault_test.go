@@ -0,0 +1,1708 @@
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/consts"
+)
+
+// fakeVaultRootToken is accepted by a fakeVault from the moment it starts.
+const fakeVaultRootToken = "root"
+
+// fakeVault is an in-process stand-in for the parts of the Vault HTTP API
+// that the provider uses, so that resources can be exercised with
+// resource.UnitTest. It keeps just enough state to behave like a dev
+// server: mounts, auth methods, audit devices, policies, tokens, KV v1 and
+// v2 secrets, and a generic key/value store for every other path under a
+// mount.
+//
+// Faults can be injected to check how the provider handles permission
+// errors, missing paths, server errors and a sealed Vault.
+type fakeVault struct {
+	t      *testing.T
+	server *httptest.Server
+
+	lock      sync.Mutex
+	version   string
+	sealed    bool
+	haEnabled bool
+	standby   bool
+	faults    []*fakeVaultFault
+	requests  []fakeVaultRequest
+	handlers  map[string]http.HandlerFunc
+	counter   int
+
+	mounts   map[string]*fakeVaultMount
+	auths    map[string]*fakeVaultMount
+	audits   map[string]*fakeVaultMount
+	policies map[string]string
+	// passwordPolicies holds the password policies by name.
+	passwordPolicies map[string]string
+	tokens           map[string]*fakeVaultToken
+	// wrapped holds the responses wrapped by Wrap, by wrapping token.
+	wrapped  map[string]*fakeVaultWrapped
+	data     map[string]map[string]interface{}
+	kv       map[string]*fakeVaultKVMetadata
+	kvConfig map[string]*fakeVaultKVConfig
+
+	// capabilities overrides the capabilities reported by
+	// sys/capabilities-self, which are otherwise those of a root token.
+	capabilities map[string][]string
+}
+
+// fakeVaultFault describes an error response to send in place of the real
+// one.
+type fakeVaultFault struct {
+	// Method restricts the fault to one HTTP method. LIST requests are
+	// reported as "LIST". All methods match when empty.
+	Method string
+
+	// Path is matched against the start of the request path, without the
+	// leading /v1/.
+	Path string
+
+	// Status is the HTTP status code to respond with.
+	Status int
+
+	// Errors are returned in the body of the response. A reasonable
+	// message is chosen from Status when empty.
+	Errors []string
+
+	// Count is the number of times the fault fires before it is cleared.
+	// Zero means the fault never clears.
+	Count int
+}
+
+// fakeVaultRequest records a request received by a fakeVault.
+type fakeVaultRequest struct {
+	Method    string
+	Path      string
+	Namespace string
+	Body      map[string]interface{}
+}
+
+type fakeVaultMount struct {
+	Type            string
+	Description     string
+	Accessor        string
+	DefaultLeaseTTL int
+	MaxLeaseTTL     int
+	Visibility      string
+	Options         map[string]string
+	Local           bool
+	SealWrap        bool
+
+	// PKI holds the state of a pki secrets engine.
+	PKI *fakeVaultPKI
+}
+
+type fakeVaultToken struct {
+	ID        string
+	Accessor  string
+	Policies  []string
+	TTL       int
+	Renewable bool
+	Meta      map[string]interface{}
+}
+
+type fakeVaultWrapped struct {
+	CreationPath string
+	CreationTime time.Time
+	TTL          int
+	Response     map[string]interface{}
+}
+
+type fakeVaultKVConfig struct {
+	MaxVersions        int
+	CASRequired        bool
+	DeleteVersionAfter string
+}
+
+type fakeVaultKVMetadata struct {
+	fakeVaultKVConfig
+	CustomMetadata map[string]interface{}
+	CreatedTime    time.Time
+	UpdatedTime    time.Time
+	CurrentVersion int
+	OldestVersion  int
+	Versions       map[int]*fakeVaultKVVersion
+}
+
+type fakeVaultKVVersion struct {
+	Data         map[string]interface{}
+	CreatedTime  time.Time
+	DeletionTime time.Time
+	Destroyed    bool
+}
+
+// newFakeVault starts a fakeVault that is shut down when the test ends.
+// Like a dev server, it has a KV version 2 engine mounted at secret/ and
+// the token auth method enabled.
+func newFakeVault(t *testing.T) *fakeVault {
+	f := &fakeVault{
+		t:                t,
+		version:          "0.11.1",
+		handlers:         map[string]http.HandlerFunc{},
+		mounts:           map[string]*fakeVaultMount{},
+		auths:            map[string]*fakeVaultMount{},
+		audits:           map[string]*fakeVaultMount{},
+		policies:         map[string]string{"default": "", "root": ""},
+		passwordPolicies: map[string]string{},
+		tokens:           map[string]*fakeVaultToken{},
+		wrapped:          map[string]*fakeVaultWrapped{},
+		data:             map[string]map[string]interface{}{},
+		kv:               map[string]*fakeVaultKVMetadata{},
+		kvConfig:         map[string]*fakeVaultKVConfig{},
+
+		capabilities: map[string][]string{},
+	}
+
+	f.tokens[fakeVaultRootToken] = &fakeVaultToken{
+		ID:       fakeVaultRootToken,
+		Accessor: f.newID("accessor"),
+		Policies: []string{"root"},
+	}
+	f.mounts["sys/"] = f.newMount("system", nil)
+	f.mounts["cubbyhole/"] = f.newMount("cubbyhole", nil)
+	f.mounts["identity/"] = f.newMount("identity", nil)
+	f.mounts["secret/"] = f.newMount("kv", map[string]string{"version": "2"})
+	f.auths["token/"] = f.newMount("token", nil)
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// Address returns the URL of the server.
+func (f *fakeVault) Address() string {
+	return f.server.URL
+}
+
+// ProviderConfig returns a provider block that points at the server, with
+// any extra settings added to it. Retries are kept, with short backoffs, so
+// that they can be tested.
+func (f *fakeVault) ProviderConfig(settings ...string) string {
+	return fmt.Sprintf(`
+provider "vault" {
+  address           = %q
+  token             = %q
+  max_retries       = 2
+  retry_min_backoff = "1ms"
+  retry_max_backoff = "2ms"
+  %s
+}
+`, f.Address(), fakeVaultRootToken, strings.Join(settings, "\n  "))
+}
+
+// Providers returns a freshly built provider for each test case, so that
+// configuring it against the fake doesn't affect testProvider.
+func (f *fakeVault) Providers() map[string]terraform.ResourceProvider {
+	return map[string]terraform.ResourceProvider{
+		"vault": Provider(),
+	}
+}
+
+// Meta returns provider meta with a client for the server, for calling
+// resource functions directly.
+func (f *fakeVault) Meta() *ProviderMeta {
+	config := api.DefaultConfig()
+	config.Address = f.Address()
+	config.MaxRetries = 0
+	client, err := api.NewClient(config)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	client.SetToken(fakeVaultRootToken)
+	return &ProviderMeta{client: client}
+}
+
+// Mount mounts a secrets engine, as sys/mounts/<path> would.
+func (f *fakeVault) Mount(path, mountType string, options map[string]string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.mounts[fakeVaultMountKey(path)] = f.newMount(mountType, options)
+}
+
+// EnableAuth enables an auth method, as sys/auth/<path> would.
+func (f *fakeVault) EnableAuth(path, authType string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.auths[fakeVaultMountKey(path)] = f.newMount(authType, nil)
+}
+
+// Write stores data at path as a client would, going through the same
+// routing as a request.
+func (f *fakeVault) Write(path string, data map[string]interface{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	status, body := f.route("PUT", path, nil, data)
+	if status >= 400 {
+		f.t.Fatalf("error writing %q to the fake Vault: %d %v", path, status, body)
+	}
+}
+
+// Read returns the data stored at path as a client would see it, or nil.
+func (f *fakeVault) Read(path string) map[string]interface{} {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	status, body := f.route("GET", path, nil, nil)
+	if status != http.StatusOK {
+		return nil
+	}
+	data, _ := body.(map[string]interface{})["data"].(map[string]interface{})
+	return data
+}
+
+// Delete removes the data stored at path as a client would.
+func (f *fakeVault) Delete(path string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.route("DELETE", path, nil, nil)
+}
+
+// HasMount reports whether a secrets engine is mounted at path.
+func (f *fakeVault) HasMount(path string) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	_, ok := f.mounts[fakeVaultMountKey(path)]
+	return ok
+}
+
+// HasAuth reports whether an auth method is enabled at path.
+func (f *fakeVault) HasAuth(path string) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	_, ok := f.auths[fakeVaultMountKey(path)]
+	return ok
+}
+
+// HasAudit reports whether an audit device is enabled at path.
+func (f *fakeVault) HasAudit(path string) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	_, ok := f.audits[fakeVaultMountKey(path)]
+	return ok
+}
+
+// Policy returns the rules of the named ACL policy, and whether it exists.
+func (f *fakeVault) Policy(name string) (string, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	policy, ok := f.policies[name]
+	return policy, ok
+}
+
+// Wrap stores response, such as {"data": {...}} or {"auth": {...}}, as if
+// a request to creationPath had been wrapped, and returns the wrapping
+// token. Like Vault's, the token can only be unwrapped once.
+func (f *fakeVault) Wrap(creationPath string, ttl int, response map[string]interface{}) string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	token := f.newID("wrapping")
+	f.wrapped[token] = &fakeVaultWrapped{
+		CreationPath: creationPath,
+		CreationTime: time.Now(),
+		TTL:          ttl,
+		Response:     response,
+	}
+	return token
+}
+
+// SetCapabilities sets the capabilities that sys/capabilities-self reports
+// for path. A path ending in * matches every path that starts with it.
+func (f *fakeVault) SetCapabilities(path string, capabilities ...string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.capabilities[path] = capabilities
+}
+
+// SetVersion sets the Vault version that the server reports.
+func (f *fakeVault) SetVersion(version string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.version = version
+}
+
+// SetStandby makes the server report that it is a node of an HA cluster,
+// and whether it is a standby or the active node.
+func (f *fakeVault) SetStandby(standby bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.haEnabled = true
+	f.standby = standby
+}
+
+// Seal makes the server turn every request away as a sealed Vault would.
+func (f *fakeVault) Seal() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.sealed = true
+}
+
+// Unseal reverses Seal.
+func (f *fakeVault) Unseal() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.sealed = false
+}
+
+// InjectFault makes matching requests fail with the described response.
+// Faults are checked in the order they were injected.
+func (f *fakeVault) InjectFault(fault fakeVaultFault) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.faults = append(f.faults, &fault)
+}
+
+// ClearFaults removes every injected fault.
+func (f *fakeVault) ClearFaults() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.faults = nil
+}
+
+// Handle serves requests for path with handler instead of the built-in
+// routes. A path ending in a slash matches every path below it.
+func (f *fakeVault) Handle(path string, handler http.HandlerFunc) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.handlers[path] = handler
+}
+
+// Requests returns every request received so far.
+func (f *fakeVault) Requests() []fakeVaultRequest {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]fakeVaultRequest(nil), f.requests...)
+}
+
+// RequestCount returns the number of requests received for method and path.
+func (f *fakeVault) RequestCount(method, path string) int {
+	count := 0
+	for _, req := range f.Requests() {
+		if req.Method == method && req.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *fakeVault) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	method := r.Method
+	if method == "GET" && r.URL.Query().Get("list") == "true" {
+		method = "LIST"
+	}
+
+	// Query parameters, such as the version of a KV secret, are handed to
+	// the routes along with the body.
+	var body map[string]interface{}
+	if raw, err := ioutil.ReadAll(r.Body); err == nil && len(raw) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			writeFakeVaultResponse(w, http.StatusBadRequest, fakeVaultErrors("failed to parse JSON input: "+err.Error()))
+			return
+		}
+	}
+	for k, v := range r.URL.Query() {
+		if k == "list" {
+			continue
+		}
+		if body == nil {
+			body = map[string]interface{}{}
+		}
+		body[k] = v[0]
+	}
+
+	f.lock.Lock()
+	f.requests = append(f.requests, fakeVaultRequest{
+		Method:    method,
+		Path:      path,
+		Namespace: r.Header.Get(consts.NamespaceHeaderName),
+		Body:      body,
+	})
+
+	if f.sealed && path != "sys/health" && path != "sys/seal-status" && path != "sys/leader" {
+		f.lock.Unlock()
+		writeFakeVaultResponse(w, http.StatusServiceUnavailable, fakeVaultErrors("Vault is sealed"))
+		return
+	}
+
+	if fault := f.matchFault(method, path); fault != nil {
+		f.lock.Unlock()
+		errs := fault.Errors
+		if len(errs) == 0 {
+			errs = fakeVaultDefaultErrors(fault.Status)
+		}
+		writeFakeVaultResponse(w, fault.Status, fakeVaultErrors(errs...))
+		return
+	}
+
+	if handler := f.matchHandler(path); handler != nil {
+		f.lock.Unlock()
+		r.Body = ioutil.NopCloser(strings.NewReader(fakeVaultMarshal(body)))
+		handler(w, r)
+		return
+	}
+
+	status, resp := f.route(method, path, r.Header, body)
+	f.lock.Unlock()
+
+	writeFakeVaultResponse(w, status, resp)
+}
+
+func (f *fakeVault) matchFault(method, path string) *fakeVaultFault {
+	for i, fault := range f.faults {
+		if fault.Method != "" && fault.Method != method {
+			continue
+		}
+		if !strings.HasPrefix(path, fault.Path) {
+			continue
+		}
+		if fault.Count > 0 {
+			fault.Count--
+			if fault.Count == 0 {
+				f.faults = append(f.faults[:i], f.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (f *fakeVault) matchHandler(path string) http.HandlerFunc {
+	if handler, ok := f.handlers[path]; ok {
+		return handler
+	}
+
+	var match string
+	for prefix := range f.handlers {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(path, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	if match == "" {
+		return nil
+	}
+	return f.handlers[match]
+}
+
+// route handles a request once it has been let through. It must be called
+// with the lock held.
+func (f *fakeVault) route(method, path string, header http.Header, body map[string]interface{}) (int, interface{}) {
+	if !f.unauthenticated(path) && header != nil {
+		if _, ok := f.tokens[header.Get(consts.AuthHeaderName)]; !ok {
+			return http.StatusForbidden, fakeVaultErrors("permission denied")
+		}
+	}
+
+	switch {
+	case path == "sys/health", path == "sys/seal-status":
+		return f.sysHealth(path, body)
+	case path == "sys/leader":
+		return f.sysLeader()
+	case path == "sys/mounts", strings.HasPrefix(path, "sys/mounts/"):
+		return f.sysMounts(method, strings.TrimPrefix(strings.TrimPrefix(path, "sys/mounts"), "/"), body)
+	case path == "sys/remount":
+		return f.sysRemount(body)
+	case path == "sys/auth", strings.HasPrefix(path, "sys/auth/"):
+		return f.sysAuth(method, strings.TrimPrefix(strings.TrimPrefix(path, "sys/auth"), "/"), body)
+	case path == "sys/audit", strings.HasPrefix(path, "sys/audit/"):
+		return f.sysAudit(method, strings.TrimPrefix(strings.TrimPrefix(path, "sys/audit"), "/"), body)
+	case strings.HasPrefix(path, "sys/audit-hash/"):
+		return f.sysAuditHash(strings.TrimPrefix(path, "sys/audit-hash/"), body)
+	case path == "sys/policy", strings.HasPrefix(path, "sys/policy/"):
+		return f.sysPolicy(method, strings.TrimPrefix(strings.TrimPrefix(path, "sys/policy"), "/"), body, "rules")
+	case path == "sys/policies/acl", strings.HasPrefix(path, "sys/policies/acl/"):
+		return f.sysPolicy(method, strings.TrimPrefix(strings.TrimPrefix(path, "sys/policies/acl"), "/"), body, "policy")
+	case strings.HasPrefix(path, "sys/policies/password/"):
+		return f.sysPasswordPolicy(method, strings.TrimPrefix(path, "sys/policies/password/"), body)
+	case strings.HasPrefix(path, "sys/wrapping/"):
+		return f.sysWrapping(strings.TrimPrefix(path, "sys/wrapping/"), header, body)
+	case path == "sys/capabilities-self":
+		return f.sysCapabilitiesSelf(body)
+	case strings.HasPrefix(path, "sys/internal/ui/mounts/"):
+		return f.sysInternalUIMounts(strings.TrimPrefix(path, "sys/internal/ui/mounts/"))
+	case strings.HasPrefix(path, "auth/token/"):
+		return f.authToken(method, strings.TrimPrefix(path, "auth/token/"), header, body)
+	case strings.HasPrefix(path, "auth/"):
+		if _, ok := f.longestMount(f.auths, strings.TrimPrefix(path, "auth/")); !ok {
+			return fakeVaultNoHandler(path)
+		}
+		return f.logical(method, path, body, true)
+	}
+
+	mountPath, ok := f.longestMount(f.mounts, path)
+	if !ok || mountPath == "sys/" {
+		return fakeVaultNoHandler(path)
+	}
+	mount := f.mounts[mountPath]
+	if mount.Type == "kv" && mount.Options["version"] == "2" {
+		return f.kvV2(method, mountPath, strings.TrimPrefix(path, mountPath), body)
+	}
+	if mount.Type == "pki" {
+		return f.pki(method, mount, strings.TrimPrefix(path, mountPath), body)
+	}
+	return f.logical(method, path, body, mount.Type != "kv" && mount.Type != "generic" && mount.Type != "cubbyhole")
+}
+
+func (f *fakeVault) unauthenticated(path string) bool {
+	return path == "sys/health" || path == "sys/seal-status" || path == "sys/leader" ||
+		(strings.HasPrefix(path, "auth/") && strings.HasSuffix(path, "/login")) ||
+		strings.Contains(path, "/login/")
+}
+
+func (f *fakeVault) sysHealth(path string, body map[string]interface{}) (int, interface{}) {
+	resp := map[string]interface{}{
+		"initialized":  true,
+		"sealed":       f.sealed,
+		"standby":      f.standby,
+		"version":      f.version,
+		"cluster_name": "vault-cluster-fake",
+		"cluster_id":   "00000000-0000-0000-0000-000000000000",
+	}
+	if path == "sys/seal-status" {
+		return http.StatusOK, resp
+	}
+
+	// Like Vault, sys/health describes the state of the node with its
+	// status code, unless the client asks for another one.
+	status := func(param string, code int) int {
+		if v, ok := body[param].(string); ok {
+			if override, err := strconv.Atoi(v); err == nil {
+				return override
+			}
+		}
+		return code
+	}
+	switch {
+	case f.sealed:
+		return status("sealedcode", http.StatusServiceUnavailable), resp
+	case f.standby:
+		return status("standbycode", http.StatusTooManyRequests), resp
+	}
+	return http.StatusOK, resp
+}
+
+func (f *fakeVault) sysLeader() (int, interface{}) {
+	resp := map[string]interface{}{
+		"ha_enabled":     f.haEnabled,
+		"is_self":        false,
+		"leader_address": "",
+	}
+	if f.haEnabled {
+		resp["is_self"] = !f.standby
+		resp["leader_address"] = "https://vault-active.fake:8200"
+	}
+	return http.StatusOK, resp
+}
+
+func (f *fakeVault) sysMounts(method, path string, body map[string]interface{}) (int, interface{}) {
+	if path == "" {
+		if method != "GET" {
+			return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+		}
+		return http.StatusOK, fakeVaultMountList(f.mounts)
+	}
+
+	tune := false
+	if strings.HasSuffix(path, "/tune") {
+		tune = true
+		path = strings.TrimSuffix(path, "/tune")
+	}
+	key := fakeVaultMountKey(path)
+
+	switch {
+	case tune && method == "GET":
+		mount, ok := f.mounts[key]
+		if !ok {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("cannot fetch sysview for path %q", key))
+		}
+		return http.StatusOK, fakeVaultData(mount.config())
+	case tune:
+		mount, ok := f.mounts[key]
+		if !ok {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("no mount entry found for path %q", key))
+		}
+		mount.tune(body)
+		return http.StatusNoContent, nil
+	case method == "DELETE":
+		if key == "sys/" {
+			return http.StatusBadRequest, fakeVaultErrors("cannot unmount \"sys/\"")
+		}
+		delete(f.mounts, key)
+		f.removeData(key)
+		return http.StatusNoContent, nil
+	case method == "POST" || method == "PUT":
+		if _, ok := f.mounts[key]; ok {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("path is already in use at %s", key))
+		}
+		mount := f.newMount(fakeVaultString(body["type"]), fakeVaultStringMap(body["options"]))
+		mount.Description = fakeVaultString(body["description"])
+		mount.Local = body["local"] == true
+		mount.SealWrap = body["seal_wrap"] == true
+		if config, ok := body["config"].(map[string]interface{}); ok {
+			mount.tune(config)
+		}
+		f.mounts[key] = mount
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+func (f *fakeVault) sysRemount(body map[string]interface{}) (int, interface{}) {
+	from := fakeVaultMountKey(fakeVaultString(body["from"]))
+	to := fakeVaultMountKey(fakeVaultString(body["to"]))
+
+	mount, ok := f.mounts[from]
+	if !ok {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("no matching mount at %q", from))
+	}
+	if _, ok := f.mounts[to]; ok {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("path already in use at %q", to))
+	}
+
+	delete(f.mounts, from)
+	f.mounts[to] = mount
+	for p, data := range f.data {
+		if strings.HasPrefix(p, from) {
+			delete(f.data, p)
+			f.data[to+strings.TrimPrefix(p, from)] = data
+		}
+	}
+	for p, meta := range f.kv {
+		if strings.HasPrefix(p, from) {
+			delete(f.kv, p)
+			f.kv[to+strings.TrimPrefix(p, from)] = meta
+		}
+	}
+	if config, ok := f.kvConfig[from]; ok {
+		delete(f.kvConfig, from)
+		f.kvConfig[to] = config
+	}
+	return http.StatusNoContent, nil
+}
+
+func (f *fakeVault) sysAuth(method, path string, body map[string]interface{}) (int, interface{}) {
+	if path == "" {
+		if method != "GET" {
+			return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+		}
+		return http.StatusOK, fakeVaultMountList(f.auths)
+	}
+
+	tune := false
+	if strings.HasSuffix(path, "/tune") {
+		tune = true
+		path = strings.TrimSuffix(path, "/tune")
+	}
+	key := fakeVaultMountKey(path)
+
+	switch {
+	case tune && method == "GET":
+		auth, ok := f.auths[key]
+		if !ok {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("cannot fetch sysview for path %q", "auth/"+key))
+		}
+		return http.StatusOK, fakeVaultData(auth.config())
+	case tune:
+		auth, ok := f.auths[key]
+		if !ok {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("no mount entry found for path %q", "auth/"+key))
+		}
+		auth.tune(body)
+		return http.StatusNoContent, nil
+	case method == "DELETE":
+		delete(f.auths, key)
+		f.removeData("auth/" + key)
+		return http.StatusNoContent, nil
+	case method == "POST" || method == "PUT":
+		if _, ok := f.auths[key]; ok {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("path is already in use at auth/%s", key))
+		}
+		auth := f.newMount(fakeVaultString(body["type"]), fakeVaultStringMap(body["options"]))
+		auth.Description = fakeVaultString(body["description"])
+		auth.Local = body["local"] == true
+		auth.SealWrap = body["seal_wrap"] == true
+		if config, ok := body["config"].(map[string]interface{}); ok {
+			auth.tune(config)
+		}
+		f.auths[key] = auth
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+func (f *fakeVault) sysAudit(method, path string, body map[string]interface{}) (int, interface{}) {
+	if path == "" {
+		if method != "GET" {
+			return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+		}
+		audits := map[string]interface{}{}
+		for key, audit := range f.audits {
+			audits[key] = map[string]interface{}{
+				"type":        audit.Type,
+				"description": audit.Description,
+				"options":     audit.Options,
+				"local":       audit.Local,
+				"path":        key,
+			}
+		}
+		return http.StatusOK, fakeVaultData(audits)
+	}
+
+	key := fakeVaultMountKey(path)
+	switch method {
+	case "DELETE":
+		delete(f.audits, key)
+		return http.StatusNoContent, nil
+	case "POST", "PUT":
+		if _, ok := f.audits[key]; ok {
+			return http.StatusBadRequest, fakeVaultErrors("path already in use")
+		}
+		audit := f.newMount(fakeVaultString(body["type"]), fakeVaultStringMap(body["options"]))
+		audit.Description = fakeVaultString(body["description"])
+		audit.Local = body["local"] == true
+		f.audits[key] = audit
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+// sysAuditHash hashes the input with a key derived from the device's path,
+// standing in for the salt of a real audit device.
+func (f *fakeVault) sysAuditHash(path string, body map[string]interface{}) (int, interface{}) {
+	key := fakeVaultMountKey(path)
+	if _, ok := f.audits[key]; !ok {
+		return http.StatusBadRequest, fakeVaultErrors("unknown audit backend " + key)
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(fakeVaultString(body["input"])))
+	return http.StatusOK, fakeVaultData(map[string]interface{}{
+		"hash": "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)),
+	})
+}
+
+func (f *fakeVault) sysPolicy(method, name string, body map[string]interface{}, field string) (int, interface{}) {
+	if name == "" {
+		names := make([]string, 0, len(f.policies))
+		for name := range f.policies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return http.StatusOK, fakeVaultData(map[string]interface{}{
+			"keys":     names,
+			"policies": names,
+		})
+	}
+
+	switch method {
+	case "GET":
+		policy, ok := f.policies[name]
+		if !ok {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		return http.StatusOK, fakeVaultData(map[string]interface{}{
+			"name": name,
+			field:  policy,
+		})
+	case "DELETE":
+		if name == "default" || name == "root" {
+			return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("cannot delete %q policy", name))
+		}
+		delete(f.policies, name)
+		return http.StatusNoContent, nil
+	case "POST", "PUT":
+		policy, ok := body["policy"].(string)
+		if !ok {
+			policy = fakeVaultString(body["rules"])
+		}
+		f.policies[name] = policy
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+// sysWrapping looks up and unwraps the responses wrapped by Wrap. Like
+// Vault, it takes the wrapping token from the body, or else uses the
+// request's token.
+func (f *fakeVault) sysWrapping(op string, header http.Header, body map[string]interface{}) (int, interface{}) {
+	token := fakeVaultString(body["token"])
+	if token == "" && header != nil {
+		token = header.Get(consts.AuthHeaderName)
+	}
+	wrapped, ok := f.wrapped[token]
+	if !ok {
+		return http.StatusBadRequest, fakeVaultErrors("wrapping token is not valid or does not exist")
+	}
+
+	switch op {
+	case "lookup":
+		return http.StatusOK, fakeVaultData(map[string]interface{}{
+			"creation_path": wrapped.CreationPath,
+			"creation_time": wrapped.CreationTime.Format(time.RFC3339Nano),
+			"creation_ttl":  wrapped.TTL,
+		})
+	case "unwrap":
+		delete(f.wrapped, token)
+		resp := map[string]interface{}{"request_id": f.newID("request")}
+		for k, v := range wrapped.Response {
+			resp[k] = v
+		}
+		return http.StatusOK, resp
+	}
+	return fakeVaultNoHandler("sys/wrapping/" + op)
+}
+
+// sysCapabilitiesSelf reports the capabilities set with SetCapabilities,
+// preferring an exact match over the longest matching glob. Other paths
+// are reported as allowed, as they would be for a root token.
+// sysPasswordPolicy stores password policies without parsing them, and
+// generates passwords that are unique but not random.
+func (f *fakeVault) sysPasswordPolicy(method, path string, body map[string]interface{}) (int, interface{}) {
+	name := strings.TrimSuffix(path, "/generate")
+	if path != name {
+		if method != "GET" {
+			return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+		}
+		if _, ok := f.passwordPolicies[name]; !ok {
+			return http.StatusBadRequest, fakeVaultErrors("policy does not exist")
+		}
+		return http.StatusOK, fakeVaultData(map[string]interface{}{"password": f.newID(name)})
+	}
+
+	switch method {
+	case "GET":
+		policy, ok := f.passwordPolicies[name]
+		if !ok {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		return http.StatusOK, fakeVaultData(map[string]interface{}{"policy": policy})
+	case "POST", "PUT":
+		f.passwordPolicies[name] = fakeVaultString(body["policy"])
+		return http.StatusNoContent, nil
+	case "DELETE":
+		delete(f.passwordPolicies, name)
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+func (f *fakeVault) sysCapabilitiesSelf(body map[string]interface{}) (int, interface{}) {
+	paths := fakeVaultStringSlice(body["paths"])
+	if p := fakeVaultString(body["path"]); p != "" {
+		paths = append(paths, p)
+	}
+
+	resp := map[string]interface{}{}
+	for _, p := range paths {
+		capabilities, ok := f.capabilities[p]
+		if !ok {
+			match := ""
+			for glob, c := range f.capabilities {
+				prefix := strings.TrimSuffix(glob, "*")
+				if strings.HasSuffix(glob, "*") && strings.HasPrefix(p, prefix) && len(prefix) >= len(match) {
+					match, capabilities, ok = prefix, c, true
+				}
+			}
+		}
+		if !ok {
+			capabilities = []string{"root"}
+		}
+		resp[p] = capabilities
+	}
+	if len(paths) == 1 {
+		resp["capabilities"] = resp[paths[0]]
+	}
+	return http.StatusOK, fakeVaultData(resp)
+}
+
+// sysInternalUIMounts describes the mount that path falls under, which the
+// KV helpers use to tell version 1 and 2 engines apart.
+func (f *fakeVault) sysInternalUIMounts(path string) (int, interface{}) {
+	mountPath, ok := f.longestMount(f.mounts, path)
+	if !ok {
+		return http.StatusBadRequest, fakeVaultErrors(fmt.Sprintf("no mount found for path %q", path))
+	}
+	mount := f.mounts[mountPath]
+	return http.StatusOK, fakeVaultData(map[string]interface{}{
+		"path":        mountPath,
+		"type":        mount.Type,
+		"description": mount.Description,
+		"accessor":    mount.Accessor,
+		"options":     mount.Options,
+		"config":      mount.config(),
+		"local":       mount.Local,
+		"seal_wrap":   mount.SealWrap,
+	})
+}
+
+func (f *fakeVault) authToken(method, path string, header http.Header, body map[string]interface{}) (int, interface{}) {
+	var self *fakeVaultToken
+	if header != nil {
+		self = f.tokens[header.Get(consts.AuthHeaderName)]
+	}
+
+	switch path {
+	case "create", "create-orphan":
+		policies := fakeVaultStringSlice(body["policies"])
+		if len(policies) == 0 && self != nil {
+			policies = self.Policies
+		}
+		token := &fakeVaultToken{
+			ID:        f.newID("token"),
+			Accessor:  f.newID("accessor"),
+			Policies:  policies,
+			TTL:       fakeVaultSeconds(body["ttl"]),
+			Renewable: body["renewable"] == nil || body["renewable"] == true,
+		}
+		if meta, ok := body["meta"].(map[string]interface{}); ok {
+			token.Meta = meta
+		}
+		f.tokens[token.ID] = token
+		return http.StatusOK, token.auth()
+	case "lookup-self":
+		if self == nil {
+			return http.StatusForbidden, fakeVaultErrors("permission denied")
+		}
+		return http.StatusOK, fakeVaultData(self.lookup())
+	case "lookup":
+		token, ok := f.tokens[fakeVaultString(body["token"])]
+		if !ok {
+			return http.StatusForbidden, fakeVaultErrors("bad token")
+		}
+		return http.StatusOK, fakeVaultData(token.lookup())
+	case "lookup-accessor", "revoke-accessor":
+		for id, token := range f.tokens {
+			if token.Accessor != fakeVaultString(body["accessor"]) {
+				continue
+			}
+			if path == "revoke-accessor" {
+				delete(f.tokens, id)
+				return http.StatusNoContent, nil
+			}
+			lookup := token.lookup()
+			lookup["id"] = ""
+			return http.StatusOK, fakeVaultData(lookup)
+		}
+		return http.StatusBadRequest, fakeVaultErrors("invalid accessor")
+	case "renew-self":
+		if self == nil {
+			return http.StatusForbidden, fakeVaultErrors("permission denied")
+		}
+		if increment := fakeVaultSeconds(body["increment"]); increment > 0 {
+			self.TTL = increment
+		}
+		return http.StatusOK, self.auth()
+	case "revoke-self":
+		if self != nil {
+			delete(f.tokens, self.ID)
+		}
+		return http.StatusNoContent, nil
+	}
+
+	return f.logical(method, "auth/token/"+path, body, true)
+}
+
+// logical serves a plain key/value store for any path under a mount. Writes
+// merge into the stored data when merge is set, which is closer to how most
+// configuration endpoints behave, and replace it otherwise, as KV version 1
+// does.
+func (f *fakeVault) logical(method, path string, body map[string]interface{}, merge bool) (int, interface{}) {
+	path = strings.TrimSuffix(path, "/")
+
+	switch method {
+	case "GET":
+		data, ok := f.data[path]
+		if !ok {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		return http.StatusOK, fakeVaultData(fakeVaultCopy(data))
+	case "LIST":
+		keys := fakeVaultListKeys(f.data, path+"/")
+		if len(keys) == 0 {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		return http.StatusOK, fakeVaultData(map[string]interface{}{"keys": keys})
+	case "DELETE":
+		delete(f.data, path)
+		return http.StatusNoContent, nil
+	case "POST", "PUT":
+		data := map[string]interface{}{}
+		if merge {
+			for k, v := range f.data[path] {
+				data[k] = v
+			}
+		}
+		for k, v := range body {
+			data[k] = v
+		}
+		f.data[path] = data
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+// kvV2 serves the versioned KV API under mountPath. path is relative to the
+// mount and starts with the API prefix, such as data/ or metadata/.
+func (f *fakeVault) kvV2(method, mountPath, path string, body map[string]interface{}) (int, interface{}) {
+	if path == "config" {
+		return f.kvV2Config(method, mountPath, body)
+	}
+
+	// Clients drop the trailing slash from the paths they list.
+	parts := strings.SplitN(path, "/", 2)
+	prefix, key := parts[0], ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+	if key == "" && !(prefix == "metadata" && method == "LIST") {
+		return fakeVaultNoHandler(mountPath + path)
+	}
+	full := mountPath + key
+
+	switch prefix {
+	case "data":
+		return f.kvV2Data(method, mountPath, full, body)
+	case "metadata":
+		return f.kvV2Metadata(method, mountPath, full, body)
+	case "delete", "undelete", "destroy":
+		meta, ok := f.kv[full]
+		if !ok {
+			return http.StatusNoContent, nil
+		}
+		for _, v := range fakeVaultIntSlice(body["versions"]) {
+			version, ok := meta.Versions[v]
+			if !ok {
+				continue
+			}
+			switch prefix {
+			case "delete":
+				if version.DeletionTime.IsZero() {
+					version.DeletionTime = time.Now().UTC()
+				}
+			case "undelete":
+				if !version.Destroyed {
+					version.DeletionTime = time.Time{}
+				}
+			case "destroy":
+				version.Destroyed = true
+				version.Data = nil
+			}
+		}
+		meta.UpdatedTime = time.Now().UTC()
+		return http.StatusNoContent, nil
+	}
+	return fakeVaultNoHandler(mountPath + path)
+}
+
+func (f *fakeVault) kvV2Config(method, mountPath string, body map[string]interface{}) (int, interface{}) {
+	config := f.kvConfig[mountPath]
+	if config == nil {
+		config = &fakeVaultKVConfig{MaxVersions: 0, DeleteVersionAfter: "0s"}
+		f.kvConfig[mountPath] = config
+	}
+
+	switch method {
+	case "GET":
+		return http.StatusOK, fakeVaultData(map[string]interface{}{
+			"max_versions":         config.MaxVersions,
+			"cas_required":         config.CASRequired,
+			"delete_version_after": config.DeleteVersionAfter,
+		})
+	case "POST", "PUT":
+		config.update(body)
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+func (f *fakeVault) kvV2Data(method, mountPath, full string, body map[string]interface{}) (int, interface{}) {
+	meta := f.kv[full]
+
+	switch method {
+	case "GET":
+		if meta == nil || meta.CurrentVersion == 0 {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		v := meta.CurrentVersion
+		if requested := fakeVaultRequestedVersion(body); requested > 0 {
+			v = requested
+		}
+		version, ok := meta.Versions[v]
+		if !ok {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		resp := map[string]interface{}{
+			"data":     fakeVaultCopy(version.Data),
+			"metadata": version.metadata(v),
+		}
+		// Like Vault, a deleted or destroyed version is reported with a 404
+		// that still carries its metadata.
+		if version.Data == nil || !version.DeletionTime.IsZero() {
+			resp["data"] = nil
+			return http.StatusNotFound, fakeVaultData(resp)
+		}
+		return http.StatusOK, fakeVaultData(resp)
+	case "POST", "PUT":
+		data, ok := body["data"].(map[string]interface{})
+		if !ok {
+			return http.StatusBadRequest, fakeVaultErrors("no data provided")
+		}
+
+		config := f.kvConfig[mountPath]
+		casRequired := config != nil && config.CASRequired
+		if meta != nil && meta.CASRequired {
+			casRequired = true
+		}
+		current := 0
+		if meta != nil {
+			current = meta.CurrentVersion
+		}
+
+		options, _ := body["options"].(map[string]interface{})
+		if cas, ok := options["cas"]; ok {
+			if int(fakeVaultNumber(cas)) != current {
+				return http.StatusBadRequest, fakeVaultErrors("check-and-set parameter did not match the current version")
+			}
+		} else if casRequired {
+			return http.StatusBadRequest, fakeVaultErrors("check-and-set parameter required for this call")
+		}
+
+		now := time.Now().UTC()
+		if meta == nil {
+			meta = &fakeVaultKVMetadata{
+				CreatedTime: now,
+				Versions:    map[int]*fakeVaultKVVersion{},
+			}
+			f.kv[full] = meta
+		}
+		if meta.OldestVersion == 0 {
+			meta.OldestVersion = 1
+		}
+		meta.CurrentVersion++
+		meta.UpdatedTime = now
+		meta.Versions[meta.CurrentVersion] = &fakeVaultKVVersion{
+			Data:        fakeVaultCopy(data),
+			CreatedTime: now,
+		}
+		meta.prune(f.kvConfig[mountPath])
+
+		return http.StatusOK, fakeVaultData(meta.Versions[meta.CurrentVersion].metadata(meta.CurrentVersion))
+	case "DELETE":
+		if meta == nil || meta.CurrentVersion == 0 {
+			return http.StatusNoContent, nil
+		}
+		if version := meta.Versions[meta.CurrentVersion]; version != nil && version.DeletionTime.IsZero() {
+			version.DeletionTime = time.Now().UTC()
+		}
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+func (f *fakeVault) kvV2Metadata(method, mountPath, full string, body map[string]interface{}) (int, interface{}) {
+	meta := f.kv[full]
+
+	switch method {
+	case "GET":
+		if meta == nil {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		versions := map[string]interface{}{}
+		for v, version := range meta.Versions {
+			versions[strconv.Itoa(v)] = map[string]interface{}{
+				"created_time":  fakeVaultTime(version.CreatedTime),
+				"deletion_time": fakeVaultTime(version.DeletionTime),
+				"destroyed":     version.Destroyed,
+			}
+		}
+		return http.StatusOK, fakeVaultData(map[string]interface{}{
+			"cas_required":         meta.CASRequired,
+			"created_time":         fakeVaultTime(meta.CreatedTime),
+			"current_version":      meta.CurrentVersion,
+			"custom_metadata":      meta.CustomMetadata,
+			"delete_version_after": meta.deleteVersionAfter(),
+			"max_versions":         meta.MaxVersions,
+			"oldest_version":       meta.OldestVersion,
+			"updated_time":         fakeVaultTime(meta.UpdatedTime),
+			"versions":             versions,
+		})
+	case "LIST":
+		prefix := full
+		if !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		keys := map[string]bool{}
+		for p := range f.kv {
+			keys[p] = true
+		}
+		list := fakeVaultListKeys(keys, prefix)
+		if len(list) == 0 {
+			return http.StatusNotFound, fakeVaultErrors()
+		}
+		return http.StatusOK, fakeVaultData(map[string]interface{}{"keys": list})
+	case "POST", "PUT":
+		now := time.Now().UTC()
+		if meta == nil {
+			meta = &fakeVaultKVMetadata{
+				CreatedTime: now,
+				Versions:    map[int]*fakeVaultKVVersion{},
+			}
+			f.kv[full] = meta
+		}
+		meta.update(body)
+		if custom, ok := body["custom_metadata"].(map[string]interface{}); ok {
+			meta.CustomMetadata = custom
+		}
+		meta.UpdatedTime = now
+		meta.prune(f.kvConfig[mountPath])
+		return http.StatusNoContent, nil
+	case "DELETE":
+		delete(f.kv, full)
+		return http.StatusNoContent, nil
+	}
+	return http.StatusMethodNotAllowed, fakeVaultErrors("unsupported operation")
+}
+
+func (f *fakeVault) longestMount(mounts map[string]*fakeVaultMount, path string) (string, bool) {
+	var match string
+	for mountPath := range mounts {
+		if (strings.HasPrefix(path, mountPath) || path == strings.TrimSuffix(mountPath, "/")) && len(mountPath) > len(match) {
+			match = mountPath
+		}
+	}
+	return match, match != ""
+}
+
+func (f *fakeVault) removeData(prefix string) {
+	for p := range f.data {
+		if strings.HasPrefix(p, prefix) {
+			delete(f.data, p)
+		}
+	}
+	for p := range f.kv {
+		if strings.HasPrefix(p, prefix) {
+			delete(f.kv, p)
+		}
+	}
+	delete(f.kvConfig, prefix)
+}
+
+func (f *fakeVault) newID(prefix string) string {
+	f.counter++
+	return fmt.Sprintf("%s-%08d", prefix, f.counter)
+}
+
+func (f *fakeVault) newMount(mountType string, options map[string]string) *fakeVaultMount {
+	return &fakeVaultMount{
+		Type:     mountType,
+		Accessor: f.newID(mountType),
+		Options:  options,
+	}
+}
+
+func (m *fakeVaultMount) config() map[string]interface{} {
+	config := map[string]interface{}{
+		"default_lease_ttl": m.DefaultLeaseTTL,
+		"max_lease_ttl":     m.MaxLeaseTTL,
+		"force_no_cache":    false,
+	}
+	if m.Visibility != "" {
+		config["listing_visibility"] = m.Visibility
+	}
+	return config
+}
+
+func (m *fakeVaultMount) tune(config map[string]interface{}) {
+	if v, ok := config["default_lease_ttl"]; ok {
+		m.DefaultLeaseTTL = fakeVaultSeconds(v)
+	}
+	if v, ok := config["max_lease_ttl"]; ok {
+		m.MaxLeaseTTL = fakeVaultSeconds(v)
+	}
+	if v, ok := config["listing_visibility"]; ok {
+		m.Visibility = fakeVaultString(v)
+	}
+	if v, ok := config["description"]; ok {
+		m.Description = fakeVaultString(v)
+	}
+	if v, ok := config["options"]; ok {
+		for k, opt := range fakeVaultStringMap(v) {
+			if m.Options == nil {
+				m.Options = map[string]string{}
+			}
+			m.Options[k] = opt
+		}
+	}
+}
+
+func (t *fakeVaultToken) auth() map[string]interface{} {
+	return map[string]interface{}{
+		"auth": map[string]interface{}{
+			"client_token":   t.ID,
+			"accessor":       t.Accessor,
+			"policies":       t.Policies,
+			"token_policies": t.Policies,
+			"metadata":       t.Meta,
+			"lease_duration": t.TTL,
+			"renewable":      t.Renewable,
+		},
+	}
+}
+
+func (t *fakeVaultToken) lookup() map[string]interface{} {
+	return map[string]interface{}{
+		"id":        t.ID,
+		"accessor":  t.Accessor,
+		"policies":  t.Policies,
+		"ttl":       t.TTL,
+		"renewable": t.Renewable,
+		"meta":      t.Meta,
+	}
+}
+
+func (c *fakeVaultKVConfig) update(body map[string]interface{}) {
+	if v, ok := body["max_versions"]; ok {
+		c.MaxVersions = int(fakeVaultNumber(v))
+	}
+	if v, ok := body["cas_required"]; ok {
+		c.CASRequired = v == true
+	}
+	if v, ok := body["delete_version_after"]; ok {
+		c.DeleteVersionAfter = (time.Duration(fakeVaultSeconds(v)) * time.Second).String()
+	}
+}
+
+func (m *fakeVaultKVMetadata) deleteVersionAfter() string {
+	if m.DeleteVersionAfter == "" {
+		return "0s"
+	}
+	return m.DeleteVersionAfter
+}
+
+// prune drops the oldest versions beyond the secret's or the mount's
+// max_versions, defaulting to ten as Vault does.
+func (m *fakeVaultKVMetadata) prune(config *fakeVaultKVConfig) {
+	max := m.MaxVersions
+	if max == 0 && config != nil {
+		max = config.MaxVersions
+	}
+	if max == 0 {
+		max = 10
+	}
+	for m.CurrentVersion-m.OldestVersion+1 > max {
+		delete(m.Versions, m.OldestVersion)
+		m.OldestVersion++
+	}
+}
+
+func (v *fakeVaultKVVersion) metadata(version int) map[string]interface{} {
+	return map[string]interface{}{
+		"created_time":  fakeVaultTime(v.CreatedTime),
+		"deletion_time": fakeVaultTime(v.DeletionTime),
+		"destroyed":     v.Destroyed,
+		"version":       version,
+	}
+}
+
+func fakeVaultMountList(mounts map[string]*fakeVaultMount) map[string]interface{} {
+	list := map[string]interface{}{}
+	for key, mount := range mounts {
+		list[key] = map[string]interface{}{
+			"type":        mount.Type,
+			"description": mount.Description,
+			"accessor":    mount.Accessor,
+			"config":      mount.config(),
+			"options":     mount.Options,
+			"local":       mount.Local,
+			"seal_wrap":   mount.SealWrap,
+		}
+	}
+	return fakeVaultData(list)
+}
+
+// fakeVaultListKeys returns the names directly below prefix, with the
+// names of folders ending in a slash.
+func fakeVaultListKeys(paths interface{}, prefix string) []string {
+	var all []string
+	switch paths := paths.(type) {
+	case map[string]map[string]interface{}:
+		for p := range paths {
+			all = append(all, p)
+		}
+	case map[string]bool:
+		for p := range paths {
+			all = append(all, p)
+		}
+	}
+
+	seen := map[string]bool{}
+	var keys []string
+	for _, p := range all {
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(p, prefix)
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[:i+1]
+		}
+		if rest != "" && !seen[rest] {
+			seen[rest] = true
+			keys = append(keys, rest)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fakeVaultData wraps data in a response body. Like Vault's list and sys
+// endpoints, the data is also repeated at the top level.
+func fakeVaultData(data map[string]interface{}) map[string]interface{} {
+	resp := map[string]interface{}{}
+	for k, v := range data {
+		resp[k] = v
+	}
+	resp["data"] = data
+	return resp
+}
+
+func fakeVaultErrors(errs ...string) map[string]interface{} {
+	if errs == nil {
+		errs = []string{}
+	}
+	return map[string]interface{}{"errors": errs}
+}
+
+func fakeVaultDefaultErrors(status int) []string {
+	switch status {
+	case http.StatusNotFound:
+		return nil
+	case http.StatusForbidden:
+		return []string{"permission denied"}
+	case http.StatusTooManyRequests:
+		return []string{"request path \"\": rate limit quota exceeded"}
+	case http.StatusServiceUnavailable:
+		return []string{"Vault is sealed"}
+	}
+	return []string{http.StatusText(status)}
+}
+
+func fakeVaultNoHandler(path string) (int, interface{}) {
+	return http.StatusNotFound, fakeVaultErrors(fmt.Sprintf("no handler for route '%s'", path))
+}
+
+func writeFakeVaultResponse(w http.ResponseWriter, status int, body interface{}) {
+	if status == http.StatusNoContent || body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func fakeVaultMarshal(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func fakeVaultMountKey(path string) string {
+	return strings.Trim(path, "/") + "/"
+}
+
+func fakeVaultTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func fakeVaultCopy(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		c[k] = v
+	}
+	return c
+}
+
+func fakeVaultString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func fakeVaultNumber(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case string:
+		n, _ := strconv.ParseFloat(v, 64)
+		return n
+	}
+	return 0
+}
+
+// fakeVaultSeconds parses a duration given either as a number of seconds or
+// as a Go duration string.
+func fakeVaultSeconds(v interface{}) int {
+	if s, ok := v.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return int(d.Seconds())
+		}
+	}
+	return int(fakeVaultNumber(v))
+}
+
+func fakeVaultStringMap(v interface{}) map[string]string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
+
+func fakeVaultStringSlice(v interface{}) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, s := range v {
+			out = append(out, fmt.Sprint(s))
+		}
+		return out
+	case string:
+		if v == "" {
+			return nil
+		}
+		return strings.Split(v, ",")
+	}
+	return nil
+}
+
+func fakeVaultIntSlice(v interface{}) []int {
+	list, _ := v.([]interface{})
+	out := make([]int, 0, len(list))
+	for _, n := range list {
+		out = append(out, int(fakeVaultNumber(n)))
+	}
+	return out
+}
+
+func fakeVaultRequestedVersion(body map[string]interface{}) int {
+	return int(fakeVaultNumber(body["version"]))
+}
+
+func TestFakeVaultKV(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("kv", "kv", map[string]string{"version": "1"})
+	client := f.Meta().GetClient()
+
+	for _, path := range []string{"kv/a", "kv/dir/b", "secret/data/a", "secret/data/dir/b"} {
+		data := map[string]interface{}{"foo": "bar"}
+		if strings.HasPrefix(path, "secret/") {
+			data = map[string]interface{}{"data": data}
+		}
+		if _, err := client.Logical().Write(path, data); err != nil {
+			t.Fatalf("error writing %q: %s", path, err)
+		}
+	}
+
+	for _, path := range []string{"kv/", "secret/metadata/"} {
+		secret, err := client.Logical().List(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := fmt.Sprint(secret.Data["keys"]), "[a dir/]"; got != want {
+			t.Errorf("bad keys for %q: want %s, got %s", path, want, got)
+		}
+	}
+
+	for _, path := range []string{"kv/a", "secret/a"} {
+		mountPath, v2, err := isKVv2(path, client, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := strings.HasPrefix(path, "secret/"); v2 != want {
+			t.Errorf("bad version for %q: want v2 %t, got %t", path, want, v2)
+		}
+		if want := strings.SplitN(path, "/", 2)[0] + "/"; mountPath != want {
+			t.Errorf("bad mount for %q: want %q, got %q", path, want, mountPath)
+		}
+	}
+
+	// Check-and-set is enforced on version 2 writes.
+	_, err := client.Logical().Write("secret/data/a", map[string]interface{}{
+		"data":    map[string]interface{}{"foo": "baz"},
+		"options": map[string]interface{}{"cas": 0},
+	})
+	if err == nil || !strings.Contains(err.Error(), "check-and-set parameter did not match") {
+		t.Fatalf("expected a check-and-set error, got %v", err)
+	}
+	if _, err := client.Logical().Write("secret/data/a", map[string]interface{}{
+		"data":    map[string]interface{}{"foo": "baz"},
+		"options": map[string]interface{}{"cas": 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := versionedSecret(1, "secret/a", client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := secret.Data["foo"]; got != "bar" {
+		t.Errorf("bad data for version 1: %v", got)
+	}
+	secret, err = versionedSecret(latestSecretVersion, "secret/a", client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := secret.Data["foo"]; got != "baz" {
+		t.Errorf("bad data for latest version: %v", got)
+	}
+
+	// Deleting the latest version leaves its metadata behind.
+	if _, err := client.Logical().Delete("secret/data/a"); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := client.Logical().Read("secret/metadata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := metadata.Data["current_version"]; fmt.Sprint(got) != "2" {
+		t.Errorf("bad current_version: %v", got)
+	}
+	if f.Read("secret/data/a") != nil {
+		t.Errorf("expected the deleted version not to be readable")
+	}
+}
@@ -0,0 +1,261 @@
+package vault
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	generatorLowercase = "abcdefghijklmnopqrstuvwxyz"
+	generatorUppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	generatorNumbers   = "0123456789"
+	generatorSymbols   = "!#$%&*+-.:=?@^_~"
+)
+
+func genericSecretGeneratorSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		Description:   "Generates the value of a key in the secret.",
+		ConflictsWith: []string{"disable_read", "allow_read", "capture_response"},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Key in the secret to generate the value of.",
+				},
+				"length": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      32,
+					ValidateFunc: validation.IntAtLeast(1),
+					Description:  "Number of characters to generate.",
+				},
+				"lowercase": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Include lowercase letters.",
+				},
+				"uppercase": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Include uppercase letters.",
+				},
+				"numbers": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Include digits.",
+				},
+				"symbols": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Include symbols.",
+				},
+				"password_policy": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Name of a Vault password policy to generate the value with, instead of the other settings.",
+				},
+			},
+		},
+	}
+}
+
+// genericSecretGenerators returns the generator blocks of a resource, by key.
+func genericSecretGenerators(d *schema.ResourceData) (map[string]map[string]interface{}, error) {
+	generators := map[string]map[string]interface{}{}
+	for _, g := range d.Get("generator").([]interface{}) {
+		g := g.(map[string]interface{})
+		key := g["key"].(string)
+		if _, ok := generators[key]; ok {
+			return nil, fmt.Errorf("more than one generator for %q", key)
+		}
+		generators[key] = g
+	}
+	return generators, nil
+}
+
+// genericSecretRotationDue reports whether every generated value must be
+// generated again. Values that a secret already holds are kept when it had
+// no generators before, such as after it was imported.
+func genericSecretRotationDue(d *schema.ResourceData) bool {
+	if d.IsNewResource() {
+		return true
+	}
+	if old, _ := d.GetChange("generator"); len(old.([]interface{})) == 0 {
+		return false
+	}
+	// The generated time is cleared by a refresh once rotate_after has
+	// passed.
+	return d.HasChange("rotation_triggers") || d.Get("generated_time").(string) == ""
+}
+
+// genericSecretRegenerate returns the keys whose values must be generated:
+// every key when rotate is set, and otherwise those missing from current,
+// and those whose generator block was added or changed since the last
+// apply. The keys of a secret that had no generators before are only
+// generated if they're missing.
+func genericSecretRegenerate(d *schema.ResourceData, generators map[string]map[string]interface{}, current map[string]interface{}, rotate bool) map[string]bool {
+	previous := map[string]interface{}{}
+	old, _ := d.GetChange("generator")
+	for _, g := range old.([]interface{}) {
+		g := g.(map[string]interface{})
+		previous[g["key"].(string)] = g
+	}
+
+	regenerate := map[string]bool{}
+	for key, g := range generators {
+		_, exists := current[key]
+		was, ok := previous[key]
+		switch {
+		case rotate, !exists:
+			regenerate[key] = true
+		case len(previous) > 0 && (!ok || !reflect.DeepEqual(was, g)):
+			log.Printf("[DEBUG] generator for %q changed", key)
+			regenerate[key] = true
+		}
+	}
+	return regenerate
+}
+
+// genericSecretRotateAfterPassed reports whether the values generated at
+// generatedTime are older than rotateAfter.
+func genericSecretRotateAfterPassed(generatedTime, rotateAfter string) (bool, error) {
+	if generatedTime == "" || rotateAfter == "" {
+		return false, nil
+	}
+	generated, err := time.Parse(time.RFC3339, generatedTime)
+	if err != nil {
+		return false, fmt.Errorf("error parsing generated_time %q: %s", generatedTime, err)
+	}
+	after, err := time.ParseDuration(rotateAfter)
+	if err != nil {
+		return false, fmt.Errorf("error parsing rotate_after %q: %s", rotateAfter, err)
+	}
+	return !time.Now().Before(generated.Add(after)), nil
+}
+
+// generateSecretValue generates a value as configured by a generator block.
+func generateSecretValue(client *api.Client, g map[string]interface{}) (string, error) {
+	if policy := g["password_policy"].(string); policy != "" {
+		return generatePolicyPassword(client, policy)
+	}
+
+	var classes []string
+	for _, c := range []struct {
+		key     string
+		charset string
+	}{
+		{"lowercase", generatorLowercase},
+		{"uppercase", generatorUppercase},
+		{"numbers", generatorNumbers},
+		{"symbols", generatorSymbols},
+	} {
+		if g[c.key].(bool) {
+			classes = append(classes, c.charset)
+		}
+	}
+	length := g["length"].(int)
+	if len(classes) == 0 {
+		return "", fmt.Errorf("generator for %q has no character classes", g["key"])
+	}
+	if length < len(classes) {
+		return "", fmt.Errorf("generator for %q needs a length of at least %d to include every character class", g["key"], len(classes))
+	}
+
+	// At least one character from each class, and the rest from any.
+	value := make([]byte, 0, length)
+	for _, charset := range classes {
+		c, err := randomChar(charset)
+		if err != nil {
+			return "", err
+		}
+		value = append(value, c)
+	}
+	all := strings.Join(classes, "")
+	for len(value) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		value = append(value, c)
+	}
+
+	for i := len(value) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		value[i], value[j.Int64()] = value[j.Int64()], value[i]
+	}
+
+	return string(value), nil
+}
+
+func randomChar(charset string) (byte, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	if err != nil {
+		return 0, fmt.Errorf("error generating a random value: %s", err)
+	}
+	return charset[i.Int64()], nil
+}
+
+// generatePolicyPassword generates a password with the named password
+// policy.
+func generatePolicyPassword(client *api.Client, policy string) (string, error) {
+	path := "sys/policies/password/" + policy + "/generate"
+
+	log.Printf("[DEBUG] Generating a password with %q", path)
+	secret, err := client.Logical().Read(path)
+	if err != nil {
+		return "", fmt.Errorf("error generating a password with %q: %s", path, err)
+	}
+	if secret == nil {
+		return "", fmt.Errorf("password policy %q not found", policy)
+	}
+	password, ok := secret.Data["password"].(string)
+	if !ok || password == "" {
+		return "", fmt.Errorf("%q did not return a password", path)
+	}
+	return password, nil
+}
+
+// planGeneratedSecretRotation adds a change to the generated time of a
+// vault_generic_secret whose refresh found that its values must be
+// generated again, so that the plan updates it.
+func planGeneratedSecretRotation(info *terraform.InstanceInfo, s *terraform.InstanceState, diff *terraform.InstanceDiff) *terraform.InstanceDiff {
+	if info.Type != "vault_generic_secret" || s == nil || s.ID == "" || s.Attributes["generated_time"] != "" {
+		return diff
+	}
+	if diff != nil && (diff.RequiresNew() || diff.GetDestroy()) {
+		return diff
+	}
+	// A secret without generators in its state, such as one that was just
+	// imported, keeps the values it holds.
+	if n := s.Attributes["generator.#"]; n == "" || n == "0" {
+		return diff
+	}
+
+	if diff == nil {
+		diff = &terraform.InstanceDiff{Attributes: map[string]*terraform.ResourceAttrDiff{}}
+	}
+	if _, ok := diff.GetAttribute("generated_time"); !ok {
+		diff.SetAttribute("generated_time", &terraform.ResourceAttrDiff{NewComputed: true})
+	}
+	return diff
+}
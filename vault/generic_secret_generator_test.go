@@ -0,0 +1,92 @@
+package vault
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateSecretValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		g       map[string]interface{}
+		charset string
+		classes []string
+		wantErr string
+	}{
+		{
+			name:    "default",
+			g:       map[string]interface{}{"length": 32, "lowercase": true, "uppercase": true, "numbers": true, "symbols": false},
+			charset: generatorLowercase + generatorUppercase + generatorNumbers,
+			classes: []string{generatorLowercase, generatorUppercase, generatorNumbers},
+		},
+		{
+			name:    "every class",
+			g:       map[string]interface{}{"length": 4, "lowercase": true, "uppercase": true, "numbers": true, "symbols": true},
+			charset: generatorLowercase + generatorUppercase + generatorNumbers + generatorSymbols,
+			classes: []string{generatorLowercase, generatorUppercase, generatorNumbers, generatorSymbols},
+		},
+		{
+			name:    "digits",
+			g:       map[string]interface{}{"length": 6, "lowercase": false, "uppercase": false, "numbers": true, "symbols": false},
+			charset: generatorNumbers,
+			classes: []string{generatorNumbers},
+		},
+		{
+			name:    "no classes",
+			g:       map[string]interface{}{"length": 6, "lowercase": false, "uppercase": false, "numbers": false, "symbols": false},
+			wantErr: "has no character classes",
+		},
+		{
+			name:    "too short",
+			g:       map[string]interface{}{"length": 2, "lowercase": true, "uppercase": true, "numbers": true, "symbols": false},
+			wantErr: "needs a length of at least 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.g["key"] = "password"
+			tt.g["password_policy"] = ""
+
+			for i := 0; i < 20; i++ {
+				value, err := generateSecretValue(nil, tt.g)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(value) != tt.g["length"].(int) {
+					t.Fatalf("expected %d characters, got %q", tt.g["length"], value)
+				}
+				if strings.Trim(value, tt.charset) != "" {
+					t.Fatalf("unexpected characters in %q", value)
+				}
+				for _, class := range tt.classes {
+					if !strings.ContainsAny(value, class) {
+						t.Fatalf("expected a character from %q in %q", class, value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSecretValueUnique(t *testing.T) {
+	g := map[string]interface{}{"key": "password", "length": 32, "lowercase": true, "uppercase": true, "numbers": true, "symbols": false, "password_policy": ""}
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		value, err := generateSecretValue(nil, g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[value] {
+			t.Fatalf("%q generated twice", value)
+		}
+		seen[value] = true
+	}
+}
@@ -1,16 +1,21 @@
 package vault
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
-func versionedSecret(requestedVersion int, path string, client *api.Client) (*api.Secret, error) {
-	mountPath, v2, err := isKVv2(path, client)
+func versionedSecret(requestedVersion int, path string, client *api.Client, serverVersion *version.Version) (*api.Secret, error) {
+	mountPath, v2, err := isKVv2(path, client, serverVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +62,10 @@ func kvReadRequest(client *api.Client, path string, params map[string]string) (*
 	if resp != nil {
 		defer resp.Body.Close()
 	}
-	if resp != nil && (resp.StatusCode == 403 || resp.StatusCode == 404) {
+	// A 404 means there's no secret at the path. Anything else, in
+	// particular a 403, is an error: treating it as a missing secret would
+	// silently drop the resource from state.
+	if resp != nil && resp.StatusCode == 404 {
 		secret, parseErr := api.ParseSecret(resp.Body)
 		switch parseErr {
 		case nil:
@@ -78,7 +86,7 @@ func kvReadRequest(client *api.Client, path string, params map[string]string) (*
 	return api.ParseSecret(resp.Body)
 }
 
-func kvPreflightVersionRequest(client *api.Client, path string) (string, int, error) {
+func kvPreflightVersionRequest(client *api.Client, path string, serverVersion *version.Version) (string, int, error) {
 	// We don't want to use a wrapping call here so save any custom value and
 	// restore after
 	currentWrappingLookupFunc := client.CurrentWrappingLookupFunc()
@@ -93,9 +101,15 @@ func kvPreflightVersionRequest(client *api.Client, path string) (string, int, er
 	if err != nil {
 		// If we get a 404 we are using an older version of vault, default to
 		// version 1
-		if resp != nil && (resp.StatusCode == 403 || resp.StatusCode == 404) {
+		if util.IsNotFound(err) {
+			if err := kvPreflightNotFound(serverVersion, path, err); err != nil {
+				return "", 0, err
+			}
 			return "", 1, nil
 		}
+		if util.IsPermissionDenied(err) {
+			return "", 0, fmt.Errorf("preflight capability check returned 403, please ensure the token's policies grant access to %q: %s", path, err)
+		}
 
 		return "", 0, err
 	}
@@ -127,13 +141,13 @@ func kvPreflightVersionRequest(client *api.Client, path string) (string, int, er
 	return mountPath, 1, nil
 }
 
-func isKVv2(path string, client *api.Client) (string, bool, error) {
-	mountPath, version, err := kvPreflightVersionRequest(client, path)
+func isKVv2(path string, client *api.Client, serverVersion *version.Version) (string, bool, error) {
+	mountPath, kvVersion, err := kvPreflightVersionRequest(client, path, serverVersion)
 	if err != nil {
 		return "", false, err
 	}
 
-	return mountPath, version == 2, nil
+	return mountPath, kvVersion == 2, nil
 }
 
 func addPrefixToVKVPath(p, mountPath, apiPrefix string) string {
@@ -145,3 +159,79 @@ func addPrefixToVKVPath(p, mountPath, apiPrefix string) string {
 		return path.Join(mountPath, apiPrefix, p)
 	}
 }
+
+// kvDurationSeconds converts a duration returned by the KV version 2
+// engine, such as "3h0m0s", into seconds.
+func kvDurationSeconds(v interface{}) (int, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		seconds, err := v.Int64()
+		return int(seconds), err
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, err
+		}
+		return int(d.Seconds()), nil
+	}
+	return 0, fmt.Errorf("unexpected duration %#v", v)
+}
+
+// kvListKeys lists the keys directly under path, which is empty when there
+// is nothing there.
+func kvListKeys(client *api.Client, path string) ([]string, error) {
+	log.Printf("[DEBUG] Listing %q", path)
+	secret, err := client.Logical().List(path)
+	if err != nil {
+		return nil, fmt.Errorf("error listing %q: %s", path, err)
+	}
+	if secret == nil {
+		return nil, nil
+	}
+
+	raw, _ := secret.Data["keys"].([]interface{})
+	keys := make([]string, 0, len(raw))
+	for _, k := range raw {
+		s, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected key %v in the list of %q", k, path)
+		}
+		keys = append(keys, s)
+	}
+	return keys, nil
+}
+
+// kvListRecursive lists the keys under path, which is in the KV mount at
+// mountPath, and under its folders down to maxDepth levels, or every level
+// when maxDepth is 0. Keys are relative to path, and folders end with a
+// slash.
+func kvListRecursive(client *api.Client, path, mountPath string, v2 bool, maxDepth int) ([]string, error) {
+	var keys []string
+	folders := []string{""}
+	for depth := 1; len(folders) > 0; depth++ {
+		var next []string
+		for _, folder := range folders {
+			listPath := strings.TrimSuffix(path+"/"+folder, "/")
+			if v2 {
+				listPath = addPrefixToVKVPath(listPath, mountPath, "metadata")
+			}
+			folderKeys, err := kvListKeys(client, listPath)
+			if err != nil {
+				return nil, err
+			}
+			for _, k := range folderKeys {
+				keys = append(keys, folder+k)
+				if strings.HasSuffix(k, "/") && (maxDepth == 0 || depth < maxDepth) {
+					next = append(next, folder+k)
+				}
+			}
+		}
+		folders = next
+	}
+	return keys, nil
+}
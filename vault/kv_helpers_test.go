@@ -0,0 +1,117 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/vault/api"
+)
+
+func testKVHelperClient(t *testing.T, handler http.HandlerFunc) (*api.Client, func()) {
+	server := httptest.NewServer(handler)
+
+	config := api.DefaultConfig()
+	config.Address = server.URL
+	config.MaxRetries = 0
+	client, err := api.NewClient(config)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	client.SetToken("test-token")
+
+	return client, server.Close
+}
+
+func TestKVReadRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		expectNil bool
+		expectErr bool
+	}{
+		{"Found", 200, `{"data":{"foo":"bar"}}`, false, false},
+		{"NotFound", 404, `{"errors":[]}`, true, false},
+		{"PermissionDenied", 403, `{"errors":["permission denied"]}`, true, true},
+		{"ServerError", 500, `{"errors":["internal error"]}`, true, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, closeFn := testKVHelperClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			})
+			defer closeFn()
+
+			secret, err := kvReadRequest(client, "secret/foo", nil)
+			if tc.expectErr && err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectNil != (secret == nil) {
+				t.Errorf("unexpected secret %#v", secret)
+			}
+		})
+	}
+}
+
+func TestKVPreflightVersionRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		serverVersion string
+		version       int
+		mountPath     string
+		expectErr     bool
+	}{
+		{"V2", 200, `{"data":{"path":"secret/","options":{"version":"2"}}}`, "", 2, "secret/", false},
+		{"V1", 200, `{"data":{"path":"kv/","options":null}}`, "", 1, "kv/", false},
+		{"OldVault", 404, `{"errors":[]}`, "0.9.6", 1, "", false},
+		{"UnknownVersion", 404, `{"errors":[]}`, "", 1, "", false},
+		{"NewVaultNotFound", 404, `{"errors":[]}`, "0.11.1", 0, "", true},
+		{"PermissionDenied", 403, `{"errors":["permission denied"]}`, "", 0, "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// The server version known to the provider is used rather
+			// than read again.
+			var serverVersion *version.Version
+			if tc.serverVersion != "" {
+				serverVersion = version.Must(version.NewVersion(tc.serverVersion))
+			}
+			client, closeFn := testKVHelperClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/sys/internal/ui/mounts/secret/foo" {
+					t.Errorf("unexpected request path %q", r.URL.Path)
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			})
+			defer closeFn()
+
+			mountPath, kvVersion, err := kvPreflightVersionRequest(client, "secret/foo", serverVersion)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kvVersion != tc.version {
+				t.Errorf("bad version: want %d, got %d", tc.version, kvVersion)
+			}
+			if mountPath != tc.mountPath {
+				t.Errorf("bad mount path: want %q, got %q", tc.mountPath, mountPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/helper/consts"
+)
+
+// kvPreflightPathPrefix is the endpoint that kvPreflightVersionRequest uses
+// to find the mount, and KV version, of a path.
+const kvPreflightPathPrefix = "sys/internal/ui/mounts/"
+
+// cachingTransport caches the mount and auth tables, and the mount details
+// returned by the KV version preflight, which resources otherwise look up
+// again for every instance. Any write to sys/mounts, sys/remount or
+// sys/auth through the provider empties the cache.
+//
+// A cachingTransport belongs to the client made by providerConfigure.
+// Terraform configures the provider at the start of each walk, so the
+// refresh, the plan and the apply each start with an empty cache, even when
+// they run in the same provider process.
+type cachingTransport struct {
+	transport http.RoundTripper
+
+	lock sync.Mutex
+
+	// generation changes whenever the cache is emptied, so that responses
+	// to requests that were in flight at the time aren't stored.
+	generation int
+
+	// entries holds responses by request, and is also used to make
+	// concurrent requests for the same table wait for a single response.
+	entries map[string]*cachedResponse
+
+	// kvMounts holds the KV preflight response for every path in a mount,
+	// by the namespace, token and mount path.
+	kvMounts map[string]*cachedResponse
+
+	// kvPending holds the KV preflight requests in flight, by the
+	// namespace, token and first segment of the path. Other preflights
+	// with the same key wait for them, in case they are for the same
+	// mount, while those for other mounts go ahead.
+	kvPending map[string]*cachedResponse
+}
+
+type cachedResponse struct {
+	// ready is closed once the response has arrived.
+	ready chan struct{}
+
+	// ok is false when the response wasn't cached, and those waiting for
+	// it must make their own request.
+	ok     bool
+	status string
+	code   int
+	header http.Header
+	body   []byte
+}
+
+func newCachingTransport(transport http.RoundTripper) http.RoundTripper {
+	return &cachingTransport{
+		transport: transport,
+		entries:   map[string]*cachedResponse{},
+		kvMounts:  map[string]*cachedResponse{},
+		kvPending: map[string]*cachedResponse{},
+	}
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1/")
+
+	if req.Method != "GET" {
+		if invalidatesLookupCache(path) {
+			t.invalidate()
+			defer t.invalidate()
+		}
+		return t.transport.RoundTrip(req)
+	}
+
+	if !isCachedLookup(path) || req.URL.RawQuery != "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	// Responses depend on the namespace, and on the token's policies.
+	scope := req.Header.Get(consts.NamespaceHeaderName) + "\x00" + req.Header.Get(consts.AuthHeaderName) + "\x00"
+	key := scope + path
+	preflight := strings.HasPrefix(path, kvPreflightPathPrefix)
+	kvPath := strings.TrimPrefix(path, kvPreflightPathPrefix)
+	pendingKey := scope + strings.SplitN(kvPath, "/", 2)[0]
+
+	t.lock.Lock()
+	entry, ok := t.entries[key]
+	for !ok && preflight {
+		entry, ok = t.kvMount(scope, kvPath)
+		if ok {
+			break
+		}
+		// A preflight in flight for a path that starts the same way may
+		// well be for the same mount.
+		pending := t.kvPending[pendingKey]
+		if pending == nil {
+			break
+		}
+		t.lock.Unlock()
+		<-pending.ready
+		t.lock.Lock()
+		entry, ok = t.entries[key]
+	}
+	if ok {
+		t.lock.Unlock()
+		<-entry.ready
+		if entry.ok {
+			log.Printf("[DEBUG] Using cached response to %s %s", req.Method, req.URL.Path)
+			return entry.response(req), nil
+		}
+		return t.transport.RoundTrip(req)
+	}
+
+	entry = &cachedResponse{ready: make(chan struct{})}
+	t.entries[key] = entry
+	if preflight {
+		t.kvPending[pendingKey] = entry
+	}
+	generation := t.generation
+	t.lock.Unlock()
+
+	defer close(entry.ready)
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.remove(key, pendingKey, entry)
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.remove(key, pendingKey, entry)
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.kvPending[pendingKey] == entry {
+		delete(t.kvPending, pendingKey)
+	}
+	if t.generation != generation {
+		return resp, nil
+	}
+	entry.ok = true
+	entry.status = resp.Status
+	entry.code = resp.StatusCode
+	entry.header = resp.Header
+	entry.body = body
+	if preflight {
+		if mountPath := kvPreflightMountPath(body); mountPath != "" {
+			t.kvMounts[scope+mountPath] = entry
+		}
+	}
+
+	return resp, nil
+}
+
+// kvMount returns the KV preflight response stored for the mount that path
+// is in. Vault doesn't allow mounts to overlap, so there's at most one.
+func (t *cachingTransport) kvMount(scope, path string) (*cachedResponse, bool) {
+	path += "/"
+	for key, entry := range t.kvMounts {
+		if strings.HasPrefix(key, scope) && strings.HasPrefix(path, strings.TrimPrefix(key, scope)) {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+func (t *cachingTransport) remove(key, pendingKey string, entry *cachedResponse) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.entries[key] == entry {
+		delete(t.entries, key)
+	}
+	if t.kvPending[pendingKey] == entry {
+		delete(t.kvPending, pendingKey)
+	}
+}
+
+func (t *cachingTransport) invalidate() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.generation++
+	t.entries = map[string]*cachedResponse{}
+	t.kvMounts = map[string]*cachedResponse{}
+}
+
+func (r *cachedResponse) response(req *http.Request) *http.Response {
+	header := http.Header{}
+	for k, v := range r.header {
+		header[k] = v
+	}
+	return &http.Response{
+		Status:        r.status,
+		StatusCode:    r.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
+
+func isCachedLookup(path string) bool {
+	return path == "sys/mounts" || path == "sys/auth" || strings.HasPrefix(path, kvPreflightPathPrefix)
+}
+
+func invalidatesLookupCache(path string) bool {
+	for _, prefix := range []string{"sys/mounts", "sys/remount", "sys/auth"} {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// kvPreflightMountPath returns the path of the mount described by a KV
+// preflight response.
+func kvPreflightMountPath(body []byte) string {
+	var resp struct {
+		Data struct {
+			Path string `json:"path"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+	if resp.Data.Path == "" || !strings.HasSuffix(resp.Data.Path, "/") {
+		return ""
+	}
+	return resp.Data.Path
+}
@@ -0,0 +1,254 @@
+package vault
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/vault/api"
+)
+
+func testCachingClient(t *testing.T, f *fakeVault) *api.Client {
+	config := api.DefaultConfig()
+	config.Address = f.Address()
+	config.MaxRetries = 0
+	config.HttpClient.Transport = newCachingTransport(http.DefaultTransport)
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken(fakeVaultRootToken)
+	return client
+}
+
+func testFakeVaultRequestsWithPrefix(f *fakeVault, method, prefix string) int {
+	count := 0
+	for _, req := range f.Requests() {
+		if req.Method == method && strings.HasPrefix(req.Path, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCachingTransportMountTables(t *testing.T) {
+	f := newFakeVault(t)
+	client := testCachingClient(t, f)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Sys().ListMounts(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Sys().ListAuth(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := f.RequestCount("GET", "sys/mounts"); n != 1 {
+		t.Errorf("expected sys/mounts to be read once, got %d", n)
+	}
+	if n := f.RequestCount("GET", "sys/auth"); n != 1 {
+		t.Errorf("expected sys/auth to be read once, got %d", n)
+	}
+
+	// Mounting through the client empties the cache.
+	if err := client.Sys().Mount("kv", &api.MountInput{Type: "kv"}); err != nil {
+		t.Fatal(err)
+	}
+	mounts, err := client.Sys().ListMounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mounts["kv/"]; !ok {
+		t.Errorf("expected the new mount to be listed, got %v", mounts)
+	}
+	if n := f.RequestCount("GET", "sys/mounts"); n != 2 {
+		t.Errorf("expected sys/mounts to be read again after a mount, got %d", n)
+	}
+	if n := f.RequestCount("GET", "sys/auth"); n != 1 {
+		t.Errorf("expected sys/auth to be read once, got %d", n)
+	}
+}
+
+func TestCachingTransportErrorsNotCached(t *testing.T) {
+	f := newFakeVault(t)
+	client := testCachingClient(t, f)
+
+	f.InjectFault(fakeVaultFault{Path: "sys/mounts", Status: http.StatusForbidden, Count: 1})
+	if _, err := client.Sys().ListMounts(); err == nil {
+		t.Fatal("expected the injected error")
+	}
+	if _, err := client.Sys().ListMounts(); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.RequestCount("GET", "sys/mounts"); n != 2 {
+		t.Errorf("expected sys/mounts to be read again after an error, got %d", n)
+	}
+}
+
+func TestCachingTransportKVPreflight(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("kv", "kv", nil)
+	client := testCachingClient(t, f)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mountPath, v2, err := isKVv2(fmt.Sprintf("secret/app-%d/config", i), client, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if mountPath != "secret/" || !v2 {
+				t.Errorf("bad preflight result: %q, %t", mountPath, v2)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := testFakeVaultRequestsWithPrefix(f, "GET", "sys/internal/ui/mounts/secret"); n != 1 {
+		t.Errorf("expected one KV preflight for secret/, got %d", n)
+	}
+
+	mountPath, v2, err := isKVv2("kv/foo", client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mountPath != "kv/" || v2 {
+		t.Errorf("bad preflight result: %q, %t", mountPath, v2)
+	}
+
+	// Upgrading the mount empties the cache.
+	if err := client.Sys().TuneMount("kv", api.MountConfigInput{Options: map[string]string{"version": "2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, v2, err := isKVv2("kv/bar", client, nil); err != nil {
+		t.Fatal(err)
+	} else if !v2 {
+		t.Errorf("expected kv/ to be reported as version 2 after the upgrade")
+	}
+	if n := testFakeVaultRequestsWithPrefix(f, "GET", "sys/internal/ui/mounts/kv"); n != 2 {
+		t.Errorf("expected two KV preflights for kv/, got %d", n)
+	}
+}
+
+func TestCachingTransportKVPreflightMounts(t *testing.T) {
+	// The preflight for a/ only completes once the one for b/ has been
+	// sent, which it can't be if preflights for other mounts wait.
+	bSent := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mount := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/v1/"+kvPreflightPathPrefix), "/", 2)[0]
+		switch mount {
+		case "a":
+			select {
+			case <-bSent:
+			case <-time.After(5 * time.Second):
+				t.Error("the KV preflight for b/ waited for the one for a/")
+			}
+		case "b":
+			close(bSent)
+		}
+		fmt.Fprintf(w, `{"data":{"path":"%s/","options":{"version":"2"}}}`, mount)
+	}))
+	defer server.Close()
+
+	config := api.DefaultConfig()
+	config.Address = server.URL
+	config.MaxRetries = 0
+	config.HttpClient.Transport = newCachingTransport(http.DefaultTransport)
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"a/foo", "b/foo"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			if _, _, err := isKVv2(path, client, nil); err != nil {
+				t.Error(err)
+			}
+		}(path)
+		time.Sleep(20 * time.Millisecond)
+	}
+	wg.Wait()
+}
+
+func testLookupCache_config() string {
+	config := ""
+	for i := 0; i < 10; i++ {
+		config += fmt.Sprintf(`
+resource "vault_generic_secret" "test_%d" {
+  path      = "secret/app-%d"
+  data_json = "{\"zip\": \"zap\"}"
+}
+`, i, i)
+	}
+	return config
+}
+
+func TestLookupCache_fakeVault(t *testing.T) {
+	preflights := map[bool]int{}
+	for _, skip := range []bool{false, true} {
+		f := newFakeVault(t)
+		resource.UnitTest(t, resource.TestCase{
+			Providers: f.Providers(),
+			Steps: []resource.TestStep{
+				{
+					Config: f.ProviderConfig(fmt.Sprintf("skip_lookup_cache = %t", skip)) + testLookupCache_config(),
+					Check:  testResourceGenericSecret_fakeVaultCheck(f, "secret/data/app-9", "zap"),
+				},
+			},
+		})
+		preflights[skip] = testFakeVaultRequestsWithPrefix(f, "GET", "sys/internal/ui/mounts/")
+	}
+
+	// Each secret is looked up at least once when it is created, and again
+	// when it is read.
+	if preflights[true] < 20 {
+		t.Errorf("expected at least 20 KV preflights without the cache, got %d", preflights[true])
+	}
+	if preflights[false] >= preflights[true]/4 {
+		t.Errorf("expected far fewer KV preflights with the cache: got %d, and %d without it", preflights[false], preflights[true])
+	}
+}
+
+func TestLookupCache_fakeVaultOperations(t *testing.T) {
+	f := newFakeVault(t)
+	preflights := func() int {
+		return testFakeVaultRequestsWithPrefix(f, "GET", "sys/internal/ui/mounts/")
+	}
+	var created int
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testLookupCache_config(),
+				Check: func(*terraform.State) error {
+					created = preflights()
+					return nil
+				},
+			},
+			{
+				// The same provider is configured again for the refresh
+				// and the plan, and neither reuses the lookups of the
+				// apply before them.
+				Config: f.ProviderConfig() + testLookupCache_config(),
+				Check: func(*terraform.State) error {
+					if n := preflights(); n <= created {
+						return fmt.Errorf("expected the secrets to be looked up again, got %d KV preflights after %d", n, created)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
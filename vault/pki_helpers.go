@@ -0,0 +1,77 @@
+package vault
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/certutil"
+)
+
+// pkiParseCertificate parses the first certificate in a PEM bundle.
+func pkiParseCertificate(pemData string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM-encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// pkiParseCSR parses a PEM-encoded certificate signing request and checks
+// its signature.
+func pkiParseCSR(pemData string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil || (block.Type != "CERTIFICATE REQUEST" && block.Type != "NEW CERTIFICATE REQUEST") {
+		return nil, fmt.Errorf("no PEM-encoded certificate request found")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid signature of certificate request: %s", err)
+	}
+	return csr, nil
+}
+
+// pkiCertificateSerial returns the serial number of cert in the format
+// Vault uses, as colon-separated hex bytes.
+func pkiCertificateSerial(cert *x509.Certificate) string {
+	return certutil.GetHexFormatted(cert.SerialNumber.Bytes(), ":")
+}
+
+// pkiStringList joins a list attribute into the comma-separated string
+// that the pki secrets engine takes.
+func pkiStringList(v interface{}) string {
+	var list []string
+	for _, s := range v.([]interface{}) {
+		list = append(list, s.(string))
+	}
+	return strings.Join(list, ",")
+}
+
+// pkiSecretBackendCASerial returns the serial number of the CA certificate
+// of the PKI backend, or an empty string if it has none.
+func pkiSecretBackendCASerial(client *api.Client, backend string) (string, error) {
+	log.Printf("[DEBUG] Reading CA certificate from PKI backend %q", backend)
+	secret, err := client.Logical().Read(backend + "/cert/ca")
+	if err != nil {
+		return "", fmt.Errorf("error reading CA certificate from PKI backend %q: %s", backend, err)
+	}
+	var pemCert string
+	if secret != nil {
+		pemCert, _ = secret.Data["certificate"].(string)
+	}
+	if pemCert == "" {
+		return "", nil
+	}
+
+	cert, err := pkiParseCertificate(pemCert)
+	if err != nil {
+		return "", fmt.Errorf("error parsing CA certificate of PKI backend %q: %s", backend, err)
+	}
+	return pkiCertificateSerial(cert), nil
+}
@@ -5,16 +5,19 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/logging"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/command/config"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"address": {
 				Type:        schema.TypeString,
@@ -24,10 +27,30 @@ func Provider() terraform.ResourceProvider {
 			},
 			"token": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("VAULT_TOKEN", ""),
 				Description: "Token to use to authenticate to Vault.",
 			},
+			"auth_login": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Login to Vault with an existing auth method, instead of using a token.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Login path of the auth method, such as auth/approle/login.",
+						},
+						"parameters": {
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Description: "Parameters to send with the login request.",
+						},
+					},
+				},
+			},
 			"ca_cert_file": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -79,6 +102,76 @@ func Provider() terraform.ResourceProvider {
 
 				Description: "Maximum TTL for secret leases requested by this provider",
 			},
+			"skip_child_token": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TERRAFORM_VAULT_SKIP_CHILD_TOKEN", false),
+				Description: "Use the given token directly instead of creating a limited child token.",
+			},
+			"renew_child_token": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TERRAFORM_VAULT_RENEW_CHILD_TOKEN", false),
+				Description: "Create a renewable child token and keep renewing it while Terraform runs.",
+			},
+			"unredacted_debug_logging": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TERRAFORM_VAULT_UNREDACTED_DEBUG_LOGGING", false),
+				Description: "Write full, unredacted Vault requests and responses to the debug log.",
+			},
+			"namespace": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VAULT_NAMESPACE", ""),
+				Description: "The namespace to use. Available only for Vault Enterprise.",
+			},
+			"max_retries": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VAULT_MAX_RETRIES", 2),
+				Description: "Maximum number of retries for requests that fail with a rate limit or server error.",
+			},
+			"retry_min_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1s",
+				ValidateFunc: util.ValidateDuration,
+				Description:  "Minimum time to wait before retrying a request, such as 500ms or 1s.",
+			},
+			"retry_max_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "30s",
+				ValidateFunc: util.ValidateDuration,
+				Description:  "Maximum time to wait before retrying a request, such as 10s or 1m.",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TERRAFORM_VAULT_MAX_CONCURRENT_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of requests sent to Vault at the same time. Unlimited when 0.",
+			},
+			"requests_per_second": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("TERRAFORM_VAULT_REQUESTS_PER_SECOND", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of requests sent to Vault per second. Unlimited when 0.",
+			},
+			"skip_lookup_cache": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TERRAFORM_VAULT_SKIP_LOOKUP_CACHE", false),
+				Description: "Look up the mount and auth tables, and the KV version of each path, again for every resource.",
+			},
+			"check_capabilities": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TERRAFORM_VAULT_CHECK_CAPABILITIES", false),
+				Description: "Check during plan that the token has the capabilities needed by each resource.",
+			},
 		},
 
 		ConfigureFunc: providerConfigure,
@@ -89,51 +182,111 @@ func Provider() terraform.ResourceProvider {
 			"vault_kubernetes_auth_backend_role":   kubernetesAuthBackendRoleDataSource(),
 			"vault_aws_access_credentials":         awsAccessCredentialsDataSource(),
 			"vault_generic_secret":                 genericSecretDataSource(),
+			"vault_kv_secrets_list":                kvSecretsListDataSource(),
+			"vault_server_info":                    serverInfoDataSource(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"vault_approle_auth_backend_login":          approleAuthBackendLoginResource(),
-			"vault_approle_auth_backend_role":           approleAuthBackendRoleResource(),
-			"vault_approle_auth_backend_role_secret_id": approleAuthBackendRoleSecretIDResource(),
-			"vault_auth_backend":                        authBackendResource(),
-			"vault_token_auth_backend_role":             tokenAuthBackendRoleResource(),
-			"vault_aws_auth_backend_cert":               awsAuthBackendCertResource(),
-			"vault_aws_auth_backend_client":             awsAuthBackendClientResource(),
-			"vault_aws_auth_backend_identity_whitelist": awsAuthBackendIdentityWhitelistResource(),
-			"vault_aws_auth_backend_login":              awsAuthBackendLoginResource(),
-			"vault_aws_auth_backend_role":               awsAuthBackendRoleResource(),
-			"vault_aws_auth_backend_role_tag":           awsAuthBackendRoleTagResource(),
-			"vault_aws_auth_backend_roletag_blacklist":  awsAuthBackendRoleTagBlacklistResource(),
-			"vault_aws_auth_backend_sts_role":           awsAuthBackendSTSRoleResource(),
-			"vault_aws_secret_backend":                  awsSecretBackendResource(),
-			"vault_aws_secret_backend_role":             awsSecretBackendRoleResource(),
-			"vault_consul_secret_backend":               consulSecretBackendResource(),
-			"vault_database_secret_backend_connection":  databaseSecretBackendConnectionResource(),
-			"vault_database_secret_backend_role":        databaseSecretBackendRoleResource(),
-			"vault_gcp_auth_backend":                    gcpAuthBackendResource(),
-			"vault_gcp_auth_backend_role":               gcpAuthBackendRoleResource(),
-			"vault_gcp_secret_backend":                  gcpSecretBackendResource(),
-			"vault_cert_auth_backend_role":              certAuthBackendRoleResource(),
-			"vault_generic_secret":                      genericSecretResource(),
-			"vault_jwt_auth_backend_role":               jwtAuthBackendRoleResource(),
-			"vault_kubernetes_auth_backend_config":      kubernetesAuthBackendConfigResource(),
-			"vault_kubernetes_auth_backend_role":        kubernetesAuthBackendRoleResource(),
-			"vault_okta_auth_backend":                   oktaAuthBackendResource(),
-			"vault_okta_auth_backend_user":              oktaAuthBackendUserResource(),
-			"vault_okta_auth_backend_group":             oktaAuthBackendGroupResource(),
-			"vault_ldap_auth_backend":                   ldapAuthBackendResource(),
-			"vault_ldap_auth_backend_user":              ldapAuthBackendUserResource(),
-			"vault_ldap_auth_backend_group":             ldapAuthBackendGroupResource(),
-			"vault_policy":                              policyResource(),
-			"vault_mount":                               mountResource(),
-			"vault_audit":                               auditResource(),
-			"vault_ssh_secret_backend_ca":               sshSecretBackendCAResource(),
-			"vault_identity_group":                      identityGroupResource(),
-			"vault_identity_group_alias":                identityGroupAliasResource(),
-			"vault_rabbitmq_secret_backend":             rabbitmqSecretBackendResource(),
-			"vault_rabbitmq_secret_backend_role":        rabbitmqSecretBackendRoleResource(),
+			"vault_approle_auth_backend_login":                   approleAuthBackendLoginResource(),
+			"vault_approle_auth_backend_role":                    approleAuthBackendRoleResource(),
+			"vault_approle_auth_backend_role_secret_id":          approleAuthBackendRoleSecretIDResource(),
+			"vault_auth_backend":                                 authBackendResource(),
+			"vault_token_auth_backend_role":                      tokenAuthBackendRoleResource(),
+			"vault_aws_auth_backend_cert":                        awsAuthBackendCertResource(),
+			"vault_aws_auth_backend_client":                      awsAuthBackendClientResource(),
+			"vault_aws_auth_backend_identity_whitelist":          awsAuthBackendIdentityWhitelistResource(),
+			"vault_aws_auth_backend_login":                       awsAuthBackendLoginResource(),
+			"vault_aws_auth_backend_role":                        awsAuthBackendRoleResource(),
+			"vault_aws_auth_backend_role_tag":                    awsAuthBackendRoleTagResource(),
+			"vault_aws_auth_backend_roletag_blacklist":           awsAuthBackendRoleTagBlacklistResource(),
+			"vault_aws_auth_backend_sts_role":                    awsAuthBackendSTSRoleResource(),
+			"vault_aws_secret_backend":                           awsSecretBackendResource(),
+			"vault_aws_secret_backend_role":                      awsSecretBackendRoleResource(),
+			"vault_consul_secret_backend":                        consulSecretBackendResource(),
+			"vault_database_secret_backend_connection":           databaseSecretBackendConnectionResource(),
+			"vault_database_secret_backend_role":                 databaseSecretBackendRoleResource(),
+			"vault_gcp_auth_backend":                             gcpAuthBackendResource(),
+			"vault_gcp_auth_backend_role":                        gcpAuthBackendRoleResource(),
+			"vault_gcp_secret_backend":                           gcpSecretBackendResource(),
+			"vault_cert_auth_backend_role":                       certAuthBackendRoleResource(),
+			"vault_generic_secret":                               genericSecretResource(),
+			"vault_jwt_auth_backend_role":                        jwtAuthBackendRoleResource(),
+			"vault_kv_secret_backend_v2":                         kvSecretBackendV2Resource(),
+			"vault_kv_secret_v2":                                 kvSecretV2Resource(),
+			"vault_kv_secret_v2_metadata":                        kvSecretV2MetadataResource(),
+			"vault_kv_tree":                                      kvTreeResource(),
+			"vault_kubernetes_auth_backend_config":               kubernetesAuthBackendConfigResource(),
+			"vault_kubernetes_auth_backend_role":                 kubernetesAuthBackendRoleResource(),
+			"vault_okta_auth_backend":                            oktaAuthBackendResource(),
+			"vault_okta_auth_backend_user":                       oktaAuthBackendUserResource(),
+			"vault_okta_auth_backend_group":                      oktaAuthBackendGroupResource(),
+			"vault_ldap_auth_backend":                            ldapAuthBackendResource(),
+			"vault_ldap_auth_backend_user":                       ldapAuthBackendUserResource(),
+			"vault_ldap_auth_backend_group":                      ldapAuthBackendGroupResource(),
+			"vault_policy":                                       policyResource(),
+			"vault_pki_secret_backend_cert":                      pkiSecretBackendCertResource(),
+			"vault_pki_secret_backend_intermediate_cert_request": pkiSecretBackendIntermediateCertRequestResource(),
+			"vault_pki_secret_backend_intermediate_set_signed":   pkiSecretBackendIntermediateSetSignedResource(),
+			"vault_pki_secret_backend_role":                      pkiSecretBackendRoleResource(),
+			"vault_pki_secret_backend_root_cert":                 pkiSecretBackendRootCertResource(),
+			"vault_pki_secret_backend_root_sign_intermediate":    pkiSecretBackendRootSignIntermediateResource(),
+			"vault_pki_secret_backend_sign":                      pkiSecretBackendSignResource(),
+			"vault_mount":                                        mountResource(),
+			"vault_audit":                                        auditResource(),
+			"vault_ssh_secret_backend_ca":                        sshSecretBackendCAResource(),
+			"vault_identity_group":                               identityGroupResource(),
+			"vault_identity_group_alias":                         identityGroupAliasResource(),
+			"vault_rabbitmq_secret_backend":                      rabbitmqSecretBackendResource(),
+			"vault_rabbitmq_secret_backend_role":                 rabbitmqSecretBackendRoleResource(),
+			"vault_unwrap":                                       unwrapResource(),
 		},
 	}
+
+	for name, r := range provider.DataSourcesMap {
+		addNamespaceSupport(name, r)
+	}
+	for name, r := range provider.ResourcesMap {
+		addNamespaceSupport(name, r)
+	}
+
+	return &vaultProvider{Provider: provider}
+}
+
+// vaultProvider checks each planned resource against the connected server
+// when Terraform asks for a diff. The schema provider doesn't give
+// resources access to the provider meta at plan time, nor tell them their
+// own address.
+type vaultProvider struct {
+	*schema.Provider
+}
+
+func (p *vaultProvider) Diff(info *terraform.InstanceInfo, s *terraform.InstanceState, c *terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
+	diff, err := p.Provider.Diff(info, s, c)
+	if err != nil {
+		return diff, err
+	}
+
+	meta, ok := p.Meta().(*ProviderMeta)
+	if !ok {
+		return diff, nil
+	}
+	if diff, err = suppressHashedDataDiff(meta, info, s, diff); err != nil {
+		return nil, err
+	}
+	diff = planGeneratedSecretRotation(info, s, diff)
+	if diff, err = planPKICertRenewal(p.Provider, info, s, c, diff); err != nil {
+		return nil, err
+	}
+	if err := checkServerVersion(meta.ServerVersion(), info, s, diff); err != nil {
+		return nil, err
+	}
+	if meta.capabilities != nil {
+		if err := meta.capabilities.checkResource(meta, info, s, diff); err != nil {
+			return nil, err
+		}
+	}
+
+	return diff, nil
 }
 
 func providerToken(d *schema.ResourceData) (string, error) {
@@ -152,6 +305,33 @@ func providerToken(d *schema.ResourceData) (string, error) {
 	return strings.TrimSpace(token), nil
 }
 
+func providerAuthLogin(client *api.Client, authLogin map[string]interface{}) (string, error) {
+	path := strings.TrimPrefix(authLogin["path"].(string), "/")
+
+	params := map[string]interface{}{}
+	if v, ok := authLogin["parameters"]; ok && v != nil {
+		for k, param := range v.(map[string]interface{}) {
+			params[k] = param
+		}
+	}
+
+	// Login endpoints are unauthenticated, so make sure any token picked
+	// up from the environment isn't sent along with the request.
+	client.ClearToken()
+
+	log.Printf("[DEBUG] Logging in to Vault using %q", path)
+	secret, err := client.Logical().Write(path, params)
+	if err != nil {
+		return "", fmt.Errorf("error logging in to Vault using %q: %s", path, err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return "", fmt.Errorf("login using %q did not return a client token", path)
+	}
+	log.Printf("[DEBUG] Logged in to Vault using %q", path)
+
+	return secret.Auth.ClientToken, nil
+}
+
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	clientConfig := api.DefaultConfig()
 	clientConfig.Address = d.Get("address").(string)
@@ -181,14 +361,50 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		return nil, fmt.Errorf("failed to configure TLS for Vault API: %s", err)
 	}
 
-	clientConfig.HttpClient.Transport = logging.NewTransport("Vault", clientConfig.HttpClient.Transport)
+	if d.Get("unredacted_debug_logging").(bool) {
+		log.Printf("[WARN] unredacted_debug_logging is set; Vault tokens and secrets will be written to the debug log")
+		clientConfig.HttpClient.Transport = logging.NewTransport("Vault", clientConfig.HttpClient.Transport)
+	} else {
+		clientConfig.HttpClient.Transport = newRedactingTransport(clientConfig.HttpClient.Transport)
+	}
+
+	// Retries are handled by our own transport rather than the API client,
+	// which only retries on a subset of server errors.
+	minBackoff, _ := time.ParseDuration(d.Get("retry_min_backoff").(string))
+	maxBackoff, _ := time.ParseDuration(d.Get("retry_max_backoff").(string))
+	if maxBackoff < minBackoff {
+		return nil, fmt.Errorf("retry_max_backoff (%s) must not be less than retry_min_backoff (%s)", maxBackoff, minBackoff)
+	}
+	// The limits apply to each attempt, but not to the wait between
+	// attempts.
+	clientConfig.HttpClient.Transport = newLimitingTransport(clientConfig.HttpClient.Transport,
+		d.Get("max_concurrent_requests").(int), d.Get("requests_per_second").(int))
+	clientConfig.HttpClient.Transport = newRetryTransport(clientConfig.HttpClient.Transport, d.Get("max_retries").(int), minBackoff, maxBackoff)
+	clientConfig.MaxRetries = 0
+
+	// The cache is made anew each time the provider is configured, which
+	// limits it to a single walk.
+	if !d.Get("skip_lookup_cache").(bool) {
+		clientConfig.HttpClient.Transport = newCachingTransport(clientConfig.HttpClient.Transport)
+	}
 
 	client, err := api.NewClient(clientConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure Vault API: %s", err)
 	}
 
-	token, err := providerToken(d)
+	// The namespace is set before authenticating so that both the login and
+	// the child token are scoped to it.
+	if namespace := strings.Trim(d.Get("namespace").(string), "/"); namespace != "" {
+		client.SetNamespace(namespace)
+	}
+
+	var token string
+	if authLoginI := d.Get("auth_login").([]interface{}); len(authLoginI) == 1 {
+		token, err = providerAuthLogin(client, authLoginI[0].(map[string]interface{}))
+	} else {
+		token, err = providerToken(d)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -196,6 +412,13 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		return nil, errors.New("no vault token found")
 	}
 
+	client.SetToken(token)
+
+	if d.Get("skip_child_token").(bool) {
+		log.Printf("[WARN] skip_child_token is set; using the given Vault token directly, without limiting its TTL")
+		return newProviderMeta(d, client), nil
+	}
+
 	// In order to enforce our relatively-short lease TTL, we derive a
 	// temporary child token that inherits all of the policies of the
 	// token we were given but expires after max_lease_ttl_seconds.
@@ -208,15 +431,22 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	// Caution is still required with state files since not all secrets
 	// can explicitly be revoked, and this limited scope won't apply to
 	// any secrets that are *written* by Terraform to Vault.
+	//
+	// When renew_child_token is set, the token has no explicit max TTL and
+	// is instead renewed for as long as Terraform is running, so that it
+	// still expires within max_lease_ttl_seconds of Terraform exiting.
 
-	client.SetToken(token)
-	renewable := false
-	childTokenLease, err := client.Auth().Token().Create(&api.TokenCreateRequest{
-		DisplayName:    "terraform",
-		TTL:            fmt.Sprintf("%ds", d.Get("max_lease_ttl_seconds").(int)),
-		ExplicitMaxTTL: fmt.Sprintf("%ds", d.Get("max_lease_ttl_seconds").(int)),
-		Renewable:      &renewable,
-	})
+	ttl := d.Get("max_lease_ttl_seconds").(int)
+	renewable := d.Get("renew_child_token").(bool)
+	tokenRequest := &api.TokenCreateRequest{
+		DisplayName: "terraform",
+		TTL:         fmt.Sprintf("%ds", ttl),
+		Renewable:   &renewable,
+	}
+	if !renewable {
+		tokenRequest.ExplicitMaxTTL = fmt.Sprintf("%ds", ttl)
+	}
+	childTokenLease, err := client.Auth().Token().Create(tokenRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create limited child token: %s", err)
 	}
@@ -228,5 +458,44 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 
 	client.SetToken(childToken)
 
-	return client, nil
+	if renewable {
+		if err := renewTokenInBackground(client, childTokenLease, ttl); err != nil {
+			return nil, err
+		}
+	}
+
+	return newProviderMeta(d, client), nil
+}
+
+// renewTokenInBackground keeps renewing the token described by secret until
+// the process exits or Vault refuses to extend it any further.
+func renewTokenInBackground(client *api.Client, secret *api.Secret, increment int) error {
+	renewer, err := client.NewRenewer(&api.RenewerInput{
+		Secret:    secret,
+		Increment: increment,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up renewal of the child token: %s", err)
+	}
+
+	go renewer.Renew()
+	go func() {
+		for {
+			select {
+			case err := <-renewer.DoneCh():
+				if err != nil {
+					log.Printf("[ERROR] Failed to renew the Vault child token: %s", err)
+				} else {
+					log.Printf("[WARN] Vault child token can no longer be renewed and will expire")
+				}
+				return
+			case renewal := <-renewer.RenewCh():
+				if renewal.Secret != nil && renewal.Secret.Auth != nil {
+					log.Printf("[DEBUG] Renewed Vault child token for %ds", renewal.Secret.Auth.LeaseDuration)
+				}
+			}
+		}
+	}()
+
+	return nil
 }
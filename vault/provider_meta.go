@@ -0,0 +1,148 @@
+package vault
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/consts"
+)
+
+// namespaceImportEnvVar can be set to the namespace of the resource being
+// imported, since the import ID alone doesn't carry it.
+const namespaceImportEnvVar = "TERRAFORM_VAULT_NAMESPACE_IMPORT"
+
+// ProviderMeta is the value handed to every resource and data source as
+// meta. It carries the configured Vault client along with any
+// provider-wide state derived from it.
+type ProviderMeta struct {
+	client *api.Client
+
+	// serverVersion is read once when the provider is configured, and is
+	// nil if it couldn't be determined.
+	serverVersion *version.Version
+
+	namespaceLock    sync.Mutex
+	namespaceClients map[string]*api.Client
+
+	// capabilities is set when the capability preflight is enabled.
+	capabilities *capabilityChecker
+}
+
+func newProviderMeta(d *schema.ResourceData, client *api.Client) *ProviderMeta {
+	meta := &ProviderMeta{client: client}
+
+	serverVersion, err := readServerVersion(client)
+	if err != nil {
+		log.Printf("[WARN] Not checking resources against the Vault server version: %s", err)
+	} else {
+		log.Printf("[INFO] Connected to Vault %s", serverVersion)
+		meta.serverVersion = serverVersion
+	}
+
+	if d.Get("check_capabilities").(bool) {
+		meta.capabilities = newCapabilityChecker()
+	}
+	return meta
+}
+
+// GetClient returns the client configured for the provider's namespace.
+func (p *ProviderMeta) GetClient() *api.Client {
+	return p.client
+}
+
+// ServerVersion returns the version of the connected Vault server, or nil
+// if it isn't known.
+func (p *ProviderMeta) ServerVersion() *version.Version {
+	return p.serverVersion
+}
+
+// namespaceClient returns a client that sends requests to the given
+// namespace, relative to the provider's namespace. Clients are created once
+// per namespace and reused.
+func (p *ProviderMeta) namespaceClient(namespace string) (*api.Client, error) {
+	namespace = strings.Trim(namespace, "/")
+	if namespace == "" {
+		return p.client, nil
+	}
+
+	p.namespaceLock.Lock()
+	defer p.namespaceLock.Unlock()
+
+	if client, ok := p.namespaceClients[namespace]; ok {
+		return client, nil
+	}
+
+	client, err := p.client.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("error cloning Vault client for namespace %q: %s", namespace, err)
+	}
+	client.SetToken(p.client.Token())
+	if headers := p.client.Headers(); headers != nil {
+		client.SetHeaders(headers)
+	}
+
+	parent := p.client.Headers().Get(consts.NamespaceHeaderName)
+	client.SetNamespace(path.Join(parent, namespace))
+
+	if p.namespaceClients == nil {
+		p.namespaceClients = map[string]*api.Client{}
+	}
+	p.namespaceClients[namespace] = client
+
+	return client, nil
+}
+
+// getClient returns the client to use for the given resource, honouring
+// its namespace attribute when one is set.
+func getClient(d *schema.ResourceData, meta interface{}) (*api.Client, error) {
+	p := meta.(*ProviderMeta)
+
+	namespace := ""
+	if v, ok := d.GetOk("namespace"); ok {
+		namespace = v.(string)
+	}
+
+	return p.namespaceClient(namespace)
+}
+
+func namespaceSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "Target namespace, relative to the provider's namespace. (requires Enterprise)",
+		StateFunc: func(v interface{}) string {
+			return strings.Trim(v.(string), "/")
+		},
+	}
+}
+
+// addNamespaceSupport adds the namespace attribute to r and makes its
+// importer pick the namespace up from the environment.
+func addNamespaceSupport(name string, r *schema.Resource) *schema.Resource {
+	if _, ok := r.Schema["namespace"]; ok {
+		panic(fmt.Sprintf("%s already defines a namespace attribute", name))
+	}
+	r.Schema["namespace"] = namespaceSchema()
+
+	if r.Importer != nil && r.Importer.State != nil {
+		importState := r.Importer.State
+		r.Importer.State = func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+			if namespace := os.Getenv(namespaceImportEnvVar); namespace != "" {
+				if err := d.Set("namespace", strings.Trim(namespace, "/")); err != nil {
+					return nil, err
+				}
+			}
+			return importState(d, meta)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,52 @@
+package vault
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/consts"
+)
+
+func TestProviderMetaNamespaceClient(t *testing.T) {
+	config := api.DefaultConfig()
+	config.Address = "http://127.0.0.1:8200"
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken("provider-token")
+	client.SetNamespace("parent")
+
+	meta := &ProviderMeta{client: client}
+
+	if c, err := meta.namespaceClient(""); err != nil {
+		t.Fatal(err)
+	} else if c != client {
+		t.Errorf("expected the provider client when no namespace is set")
+	}
+
+	nsClient, err := meta.namespaceClient("/team-a/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nsClient == client {
+		t.Fatalf("expected a separate client for namespace")
+	}
+	if got, want := nsClient.Headers().Get(consts.NamespaceHeaderName), "parent/team-a"; got != want {
+		t.Errorf("bad namespace header: want %q, got %q", want, got)
+	}
+	if got, want := nsClient.Token(), "provider-token"; got != want {
+		t.Errorf("bad token: want %q, got %q", want, got)
+	}
+	if got, want := client.Headers().Get(consts.NamespaceHeaderName), "parent"; got != want {
+		t.Errorf("provider client namespace was modified: want %q, got %q", want, got)
+	}
+
+	again, err := meta.namespaceClient("team-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != nsClient {
+		t.Errorf("expected the namespace client to be reused")
+	}
+}
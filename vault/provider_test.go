@@ -1,14 +1,20 @@
 package vault
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/command/config"
 	"github.com/mitchellh/go-homedir"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
@@ -41,16 +47,18 @@ import (
 // start over with a fresh Vault. (Remember to reset VAULT_TOKEN.)
 
 func TestProvider(t *testing.T) {
-	if err := Provider().(*schema.Provider).InternalValidate(); err != nil {
+	if err := Provider().(*vaultProvider).InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
 	}
 }
 
-var testProvider *schema.Provider
+// testProvider keeps the Diff of vaultProvider, so that acceptance tests go
+// through the same plan-time checks as Terraform does.
+var testProvider *vaultProvider
 var testProviders map[string]terraform.ResourceProvider
 
 func init() {
-	testProvider = Provider().(*schema.Provider)
+	testProvider = Provider().(*vaultProvider)
 	testProviders = map[string]terraform.ResourceProvider{
 		"vault": testProvider,
 	}
@@ -151,7 +159,7 @@ func TestAccProviderToken(t *testing.T) {
 	}
 
 	// Create a "resource" we can use for constructing ResourceData.
-	provider := Provider().(*schema.Provider)
+	provider := Provider().(*vaultProvider).Provider
 	providerResource := &schema.Resource{
 		Schema: provider.Schema,
 	}
@@ -261,3 +269,263 @@ func TestAccProviderToken(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderAuthLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/approle/login" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if token := r.Header.Get("X-Vault-Token"); token != "" {
+			t.Errorf("login request should not send a token, got %q", token)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body["role_id"] != "my-role" || body["secret_id"] != "my-secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+		w.Write([]byte(`{"auth":{"client_token":"login-token","policies":["default"]}}`))
+	}))
+	defer server.Close()
+
+	type testcase struct {
+		name          string
+		path          string
+		parameters    map[string]interface{}
+		expectedToken string
+		expectErr     bool
+	}
+
+	tests := []testcase{
+		{
+			name: "Success",
+			path: "auth/approle/login",
+			parameters: map[string]interface{}{
+				"role_id":   "my-role",
+				"secret_id": "my-secret",
+			},
+			expectedToken: "login-token",
+		},
+		{
+			name: "LeadingSlash",
+			path: "/auth/approle/login",
+			parameters: map[string]interface{}{
+				"role_id":   "my-role",
+				"secret_id": "my-secret",
+			},
+			expectedToken: "login-token",
+		},
+		{
+			name: "BadCredentials",
+			path: "auth/approle/login",
+			parameters: map[string]interface{}{
+				"role_id":   "my-role",
+				"secret_id": "wrong",
+			},
+			expectErr: true,
+		},
+		{
+			name:      "UnknownPath",
+			path:      "auth/userpass/login/bob",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := api.DefaultConfig()
+			config.Address = server.URL
+			client, err := api.NewClient(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.SetToken("environment-token")
+
+			token, err := providerAuthLogin(client, map[string]interface{}{
+				"path":       tc.path,
+				"parameters": tc.parameters,
+			})
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != tc.expectedToken {
+				t.Errorf("bad token value: want %#v, got %#v", tc.expectedToken, token)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureChildToken(t *testing.T) {
+	type testcase struct {
+		name             string
+		config           map[string]interface{}
+		expectCreate     bool
+		expectRenewable  bool
+		expectedMaxTTL   string
+		expectRenewal    bool
+		expectedMetaAuth string
+	}
+
+	tests := []testcase{
+		{
+			name:             "Default",
+			config:           map[string]interface{}{},
+			expectCreate:     true,
+			expectedMaxTTL:   "1200s",
+			expectedMetaAuth: "child-token",
+		},
+		{
+			name: "Renewable",
+			config: map[string]interface{}{
+				"renew_child_token": true,
+			},
+			expectCreate:     true,
+			expectRenewable:  true,
+			expectRenewal:    true,
+			expectedMetaAuth: "child-token",
+		},
+		{
+			name: "SkipChildToken",
+			config: map[string]interface{}{
+				"skip_child_token": true,
+			},
+			expectedMetaAuth: "parent-token",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			created := make(chan api.TokenCreateRequest, 1)
+			renewed := make(chan struct{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/auth/token/create":
+					var req api.TokenCreateRequest
+					if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+						t.Fatal(err)
+					}
+					created <- req
+					renewable := req.Renewable != nil && *req.Renewable
+					fmt.Fprintf(w, `{"auth":{"client_token":"child-token","policies":["default"],"lease_duration":1200,"renewable":%t}}`, renewable)
+				case "/v1/auth/token/renew-self":
+					select {
+					case renewed <- struct{}{}:
+					default:
+					}
+					w.Write([]byte(`{"auth":{"client_token":"child-token","lease_duration":1200,"renewable":true}}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			raw := map[string]interface{}{
+				"address": server.URL,
+				"token":   "parent-token",
+			}
+			for k, v := range tc.config {
+				raw[k] = v
+			}
+			d := schema.TestResourceDataRaw(t, Provider().(*vaultProvider).Schema, raw)
+
+			meta, err := providerConfigure(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := meta.(*ProviderMeta).GetClient().Token(); got != tc.expectedMetaAuth {
+				t.Errorf("bad client token: want %q, got %q", tc.expectedMetaAuth, got)
+			}
+
+			select {
+			case req := <-created:
+				if !tc.expectCreate {
+					t.Fatalf("unexpected child token request")
+				}
+				if renewable := req.Renewable != nil && *req.Renewable; renewable != tc.expectRenewable {
+					t.Errorf("bad renewable: want %t, got %t", tc.expectRenewable, renewable)
+				}
+				if req.ExplicitMaxTTL != tc.expectedMaxTTL {
+					t.Errorf("bad explicit max TTL: want %q, got %q", tc.expectedMaxTTL, req.ExplicitMaxTTL)
+				}
+			default:
+				if tc.expectCreate {
+					t.Fatalf("expected a child token to be created")
+				}
+			}
+
+			if tc.expectRenewal {
+				select {
+				case <-renewed:
+				case <-time.After(5 * time.Second):
+					t.Fatalf("expected the child token to be renewed")
+				}
+			}
+		})
+	}
+}
+
+func TestProviderConfigureRequestLimits(t *testing.T) {
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		inFlight--
+		lock.Unlock()
+		w.Write([]byte(`{"data":{"foo":"bar"}}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().(*vaultProvider).Schema, map[string]interface{}{
+		"address":                 server.URL,
+		"token":                   "parent-token",
+		"skip_child_token":        true,
+		"max_concurrent_requests": 1,
+	})
+	meta, err := providerConfigure(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clients for other namespaces share the limit.
+	nsClient, err := meta.(*ProviderMeta).namespaceClient("team-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, client := range []*api.Client{meta.(*ProviderMeta).GetClient(), nsClient} {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func(client *api.Client) {
+				defer wg.Done()
+				if _, err := client.Logical().Read("secret/foo"); err != nil {
+					t.Error(err)
+				}
+			}(client)
+		}
+	}
+	wg.Wait()
+
+	if maxInFlight != 1 {
+		t.Errorf("bad maximum number of concurrent requests: want 1, got %d", maxInFlight)
+	}
+}
@@ -88,7 +88,10 @@ func approleAuthBackendLoginResource() *schema.Resource {
 }
 
 func approleAuthBackendLoginCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 
@@ -116,15 +119,14 @@ func approleAuthBackendLoginCreate(d *schema.ResourceData, meta interface{}) err
 }
 
 func approleAuthBackendLoginRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	log.Printf("[DEBUG] Reading token %q", d.Id())
 	resp, err := client.Auth().Token().LookupAccessor(d.Id())
-	if err != nil {
-		// If the token is not found (it has expired) we don't return an error
-		if util.IsExpiredTokenErr(err) {
-			return nil
-		}
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading token %q from Vault: %s", d.Id(), err)
 	}
 	if resp == nil {
@@ -155,11 +157,14 @@ func approleAuthBackendLoginRead(d *schema.ResourceData, meta interface{}) error
 }
 
 func approleAuthBackendLoginDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	accessor := d.Id()
 
 	log.Printf("[DEBUG] Revoking token %q", accessor)
-	err := client.Auth().Token().RevokeAccessor(accessor)
+	err = client.Auth().Token().RevokeAccessor(accessor)
 	if err != nil {
 		return fmt.Errorf("error revoking token %q", accessor)
 	}
@@ -169,16 +174,15 @@ func approleAuthBackendLoginDelete(d *schema.ResourceData, meta interface{}) err
 }
 
 func approleAuthBackendLoginExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 	accessor := d.Id()
 
 	log.Printf("[DEBUG] Checking if token %q exists", accessor)
 	resp, err := client.Auth().Token().LookupAccessor(accessor)
-	if err != nil {
-		// If the token is not found (it has expired) we don't return an error
-		if util.IsExpiredTokenErr(err) {
-			return false, nil
-		}
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error reading %q: %s", accessor, err)
 	}
 	return resp != nil, nil
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
 	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
@@ -111,7 +110,10 @@ func approleAuthBackendRoleResource() *schema.Resource {
 }
 
 func approleAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	role := d.Get("role_name").(string)
@@ -160,7 +162,7 @@ func approleAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) erro
 		data["token_max_ttl"] = v.(int)
 	}
 
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("error writing AppRole auth backend role %q: %s", path, err)
 	}
@@ -182,7 +184,10 @@ func approleAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) erro
 }
 
 func approleAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	backend, err := approleAuthBackendRoleBackendFromPath(path)
@@ -197,7 +202,7 @@ func approleAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error
 
 	log.Printf("[DEBUG] Reading AppRole auth backend role %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading AppRole auth backend role %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read AppRole auth backend role %q", path)
@@ -277,7 +282,7 @@ func approleAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error
 
 	log.Printf("[DEBUG] Reading AppRole auth backend role %q RoleID", path)
 	resp, err = client.Logical().Read(path + "/role-id")
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading AppRole auth backend role %q RoleID: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read AppRole auth backend role %q RoleID", path)
@@ -289,7 +294,10 @@ func approleAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error
 }
 
 func approleAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Updating AppRole auth backend role %q", path)
@@ -317,7 +325,7 @@ func approleAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) erro
 		"period":             d.Get("period").(int),
 	}
 
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 
 	d.SetId(path)
 
@@ -342,12 +350,15 @@ func approleAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) erro
 }
 
 func approleAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting AppRole auth backend role %q", path)
-	_, err := client.Logical().Delete(path)
-	if err != nil && !util.Is404(err) {
+	_, err = client.Logical().Delete(path)
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error deleting AppRole auth backend role %q", path)
 	} else if err != nil {
 		log.Printf("[DEBUG] AppRole auth backend role %q not found, removing from state", path)
@@ -360,13 +371,16 @@ func approleAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) erro
 }
 
 func approleAuthBackendRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if AppRole auth backend role %q exists", path)
 
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if AppRole auth backend role %q exists: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if AppRole auth backend role %q exists", path)
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
 	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
@@ -90,7 +89,10 @@ func approleAuthBackendRoleSecretIDResource() *schema.Resource {
 }
 
 func approleAuthBackendRoleSecretIDCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	role := d.Get("role_name").(string)
@@ -137,7 +139,10 @@ func approleAuthBackendRoleSecretIDCreate(d *schema.ResourceData, meta interface
 }
 
 func approleAuthBackendRoleSecretIDRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	id := d.Id()
 
 	backend, role, accessor, err := approleAuthBackendRoleSecretIDParseID(id)
@@ -151,11 +156,7 @@ func approleAuthBackendRoleSecretIDRead(d *schema.ResourceData, meta interface{}
 	resp, err := client.Logical().Write(path, map[string]interface{}{
 		"secret_id_accessor": accessor,
 	})
-	if err != nil {
-		// We need to check if the secret_id has expired
-		if util.IsExpiredTokenErr(err) {
-			return nil
-		}
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading AppRole auth backend role SecretID %q: %s", id, err)
 	}
 	log.Printf("[DEBUG] Read AppRole auth backend role SecretID %q", id)
@@ -199,7 +200,10 @@ func approleAuthBackendRoleSecretIDRead(d *schema.ResourceData, meta interface{}
 }
 
 func approleAuthBackendRoleSecretIDDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	id := d.Id()
 	backend, role, accessor, err := approleAuthBackendRoleSecretIDParseID(id)
 	if err != nil {
@@ -221,7 +225,10 @@ func approleAuthBackendRoleSecretIDDelete(d *schema.ResourceData, meta interface
 }
 
 func approleAuthBackendRoleSecretIDExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 	id := d.Id()
 
 	backend, role, accessor, err := approleAuthBackendRoleSecretIDParseID(id)
@@ -235,11 +242,7 @@ func approleAuthBackendRoleSecretIDExists(d *schema.ResourceData, meta interface
 	resp, err := client.Logical().Write(path, map[string]interface{}{
 		"secret_id_accessor": accessor,
 	})
-	if err != nil {
-		// We need to check if the secret_id has expired
-		if util.IsExpiredTokenErr(err) {
-			return true, nil
-		}
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if AppRole auth backend role SecretID %q exists: %s", id, err)
 	}
 	log.Printf("[DEBUG] Checked if AppRole auth backend role SecretID %q exists", id)
@@ -7,7 +7,6 @@ import (
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/hashicorp/vault/api"
 )
 
 func TestAccAppRoleAuthBackendRoleSecretID_basic(t *testing.T) {
@@ -66,7 +65,7 @@ func TestAccAppRoleAuthBackendRoleSecretID_full(t *testing.T) {
 }
 
 func testAccCheckAppRoleAuthBackendRoleSecretIDDestroy(s *terraform.State) error {
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "vault_approle_auth_backend_role_secret_id" {
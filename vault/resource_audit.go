@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
 )
 
 func auditResource() *schema.Resource {
@@ -53,7 +52,10 @@ func auditResource() *schema.Resource {
 }
 
 func auditWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Get("path").(string)
 	if path == "" {
@@ -84,7 +86,10 @@ func auditWrite(d *schema.ResourceData, meta interface{}) error {
 }
 
 func auditDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -98,7 +103,10 @@ func auditDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func auditRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -81,7 +81,7 @@ func testResourceAudit_initialCheck(expectedPath string) resource.TestCheckFunc
 }
 
 func findAudit(path string) (*api.Audit, error) {
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 
 	path = path + "/"
 
@@ -96,3 +96,41 @@ func findAudit(path string) (*api.Audit, error) {
 
 	return nil, fmt.Errorf("unable to find audit %s in Vault; current list: %v", path, audits)
 }
+
+func TestResourceAudit_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(s *terraform.State) error {
+			if f.HasAudit("example") {
+				return fmt.Errorf("audit device still exists")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceAudit_initialConfig("example"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_audit.test", "path", "example"),
+					resource.TestCheckResourceAttr("vault_audit.test", "type", "file"),
+					resource.TestCheckResourceAttr("vault_audit.test", "description", "Example file audit for vault"),
+					resource.TestCheckResourceAttr("vault_audit.test", "options.path", "stdout"),
+				),
+			},
+			{
+				// An audit device disabled outside of Terraform is enabled
+				// again.
+				PreConfig: func() {
+					f.Delete("sys/audit/example")
+				},
+				Config: f.ProviderConfig() + testResourceAudit_initialConfig("example"),
+				Check: func(s *terraform.State) error {
+					if !f.HasAudit("example") {
+						return fmt.Errorf("audit device was not enabled again")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/hashicorp/vault/api"
 )
 
 func TestResourceAuth(t *testing.T) {
@@ -29,7 +28,7 @@ func TestResourceAuth(t *testing.T) {
 }
 
 func testAccCheckAuthBackendDestroy(s *terraform.State) error {
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 
 	auths, err := client.Sys().ListAuth()
 	if err != nil {
@@ -110,7 +109,7 @@ func testResourceAuth_initialCheck(expectedPath string) resource.TestCheckFunc {
 			return fmt.Errorf("unexpected auth local")
 		}
 
-		client := testProvider.Meta().(*api.Client)
+		client := testProvider.Meta().(*ProviderMeta).GetClient()
 		auths, err := client.Sys().ListAuth()
 
 		if err != nil {
@@ -180,7 +179,7 @@ func testResourceAuth_updateCheck(s *terraform.State) error {
 		return fmt.Errorf("unexpected auth name")
 	}
 
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 	auths, err := client.Sys().ListAuth()
 
 	if err != nil {
@@ -204,3 +203,43 @@ func testResourceAuth_updateCheck(s *terraform.State) error {
 
 	return nil
 }
+
+func TestResourceAuth_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(s *terraform.State) error {
+			if f.HasAuth("github-test") || f.HasAuth("ldap") {
+				return fmt.Errorf("auth backend still exists")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceAuth_initialConfig("github-test"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_auth_backend.test", "id", "github-test"),
+					resource.TestCheckResourceAttr("vault_auth_backend.test", "path", "github-test/"),
+					resource.TestCheckResourceAttr("vault_auth_backend.test", "type", "github"),
+					resource.TestCheckResourceAttr("vault_auth_backend.test", "default_lease_ttl_seconds", "3600"),
+					resource.TestCheckResourceAttr("vault_auth_backend.test", "max_lease_ttl_seconds", "86400"),
+					resource.TestCheckResourceAttr("vault_auth_backend.test", "listing_visibility", "unauth"),
+					resource.TestCheckResourceAttr("vault_auth_backend.test", "local", "true"),
+				),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceAuth_updateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_auth_backend.test", "id", "ldap"),
+					resource.TestCheckResourceAttrSet("vault_auth_backend.test", "accessor"),
+					func(s *terraform.State) error {
+						if f.HasAuth("github-test") {
+							return fmt.Errorf("replaced auth backend still exists")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
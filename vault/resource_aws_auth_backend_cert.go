@@ -9,7 +9,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 var (
@@ -64,7 +64,10 @@ func awsAuthBackendCertResource() *schema.Resource {
 }
 
 func awsAuthBackendCertCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	certType := d.Get("type").(string)
@@ -74,7 +77,7 @@ func awsAuthBackendCertCreate(d *schema.ResourceData, meta interface{}) error {
 	path := awsAuthBackendCertPath(backend, name)
 
 	log.Printf("[DEBUG] Writing cert %q to AWS auth backend", path)
-	_, err := client.Logical().Write(path, map[string]interface{}{
+	_, err = client.Logical().Write(path, map[string]interface{}{
 		"aws_public_cert": publicCert,
 		"type":            certType,
 	})
@@ -91,7 +94,10 @@ func awsAuthBackendCertCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendCertRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -107,7 +113,7 @@ func awsAuthBackendCertRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Reading cert %q from AWS auth backend", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading AWS auth backend cert %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read cert %q from AWS auth backend", path)
@@ -130,11 +136,14 @@ func awsAuthBackendCertRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendCertDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Removing cert %q from AWS auth backend", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("error deleting AWS auth backend cert %q: %s", path, err)
 	}
@@ -144,13 +153,16 @@ func awsAuthBackendCertDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendCertExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 
 	log.Printf("[DEBUG] Checking if cert %q exists in AWS auth backend", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking for existence of AWS auth backend cert %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if cert %q exists in AWS auth backend", path)
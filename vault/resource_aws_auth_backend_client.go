@@ -6,7 +6,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 func awsAuthBackendClientResource() *schema.Resource {
@@ -69,7 +69,10 @@ func awsAuthBackendClientResource() *schema.Resource {
 }
 
 func awsAuthBackendWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	// if backend comes from the config, it won't have the StateFunc
 	// applied yet, so we need to apply it again.
@@ -93,7 +96,7 @@ func awsAuthBackendWrite(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	log.Printf("[DEBUG] Writing AWS auth backend client config to %q", path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("error writing to %q: %s", path, err)
 	}
@@ -105,11 +108,14 @@ func awsAuthBackendWrite(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	log.Printf("[DEBUG] Reading AWS auth backend client config")
 	secret, err := client.Logical().Read(d.Id())
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading AWS auth backend client config from %q: %s", d.Id(), err)
 	}
 	log.Printf("[DEBUG] Read AWS auth backend client config")
@@ -133,10 +139,13 @@ func awsAuthBackendRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	log.Printf("[DEBUG] Deleting AWS auth backend client config from %q", d.Id())
-	_, err := client.Logical().Delete(d.Id())
+	_, err = client.Logical().Delete(d.Id())
 	if err != nil {
 		return fmt.Errorf("error deleting AWS auth backend client config from %q: %s", d.Id(), err)
 	}
@@ -146,11 +155,14 @@ func awsAuthBackendDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	log.Printf("[DEBUG] Checking if AWS auth backend client is configured at %q", d.Id())
 	secret, err := client.Logical().Read(d.Id())
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if AWS auth backend client is configured at %q: %s", d.Id(), err)
 	}
 	log.Printf("[DEBUG] Checked if AWS auth backend client is configured at %q", d.Id())
@@ -7,7 +7,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 var (
@@ -52,7 +52,10 @@ func awsAuthBackendIdentityWhitelistResource() *schema.Resource {
 }
 
 func awsAuthBackendIdentityWhitelistWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	data := map[string]interface{}{}
@@ -67,7 +70,7 @@ func awsAuthBackendIdentityWhitelistWrite(d *schema.ResourceData, meta interface
 	path := awsAuthBackendIdentityWhitelistPath(backend)
 
 	log.Printf("[DEBUG] Configuring AWS auth backend identity whitelist %q", path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 
 	d.SetId(path)
 
@@ -81,7 +84,10 @@ func awsAuthBackendIdentityWhitelistWrite(d *schema.ResourceData, meta interface
 }
 
 func awsAuthBackendIdentityWhitelistRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -92,7 +98,7 @@ func awsAuthBackendIdentityWhitelistRead(d *schema.ResourceData, meta interface{
 
 	log.Printf("[DEBUG] Reading identity whitelist %q from AWS auth backend", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading AWS auth backend identity whitelist %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read identity whitelist %q from AWS auth backend", path)
@@ -110,11 +116,14 @@ func awsAuthBackendIdentityWhitelistRead(d *schema.ResourceData, meta interface{
 }
 
 func awsAuthBackendIdentityWhitelistDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Removing identity whitelist %q from AWS auth backend", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("error deleting AWS auth backend identity whitelist %q: %s", path, err)
 	}
@@ -124,13 +133,16 @@ func awsAuthBackendIdentityWhitelistDelete(d *schema.ResourceData, meta interfac
 }
 
 func awsAuthBackendIdentityWhitelistExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 
 	log.Printf("[DEBUG] Checking if identity whitelist %q exists in AWS auth backend", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking for existence of AWS auth backend identity whitelist %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if identity whitelist %q exists in AWS auth backend", path)
@@ -7,8 +7,6 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
-
-	"github.com/hashicorp/vault/api"
 )
 
 func awsAuthBackendLoginResource() *schema.Resource {
@@ -150,7 +148,10 @@ func awsAuthBackendLoginCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendLoginRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := strings.Trim(d.Get("backend").(string), "/")
 	path := "auth/" + backend + "/login"
@@ -231,7 +232,10 @@ func awsAuthBackendLoginRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendLoginDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	accessor := d.Get("accessor").(string)
 	token, ok := d.GetOk("client_token")
@@ -240,7 +244,7 @@ func awsAuthBackendLoginDelete(d *schema.ResourceData, meta interface{}) error {
 		return nil
 	}
 	log.Printf("[DEBUG] Revoking token %q", accessor)
-	err := client.Auth().Token().RevokeTree(token.(string))
+	err = client.Auth().Token().RevokeTree(token.(string))
 	if err != nil {
 		log.Printf("[ERROR] Error revoking token %q: %s", accessor, err)
 		return err
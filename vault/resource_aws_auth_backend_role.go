@@ -8,7 +8,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 var (
@@ -270,7 +270,10 @@ func setSlice(d *schema.ResourceData, tfFieldName, vaultFieldName string, data m
 }
 
 func awsAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	role := d.Get("role").(string)
@@ -394,7 +397,10 @@ func awsAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	backend, err := awsAuthBackendRoleBackendFromPath(path)
@@ -409,7 +415,7 @@ func awsAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Reading AWS auth backend role %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading AWS auth backend role %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read AWS auth backend role %q", path)
@@ -512,7 +518,10 @@ func awsAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Updating AWS auth backend role %q", path)
@@ -622,7 +631,7 @@ func awsAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("error updating AWS auth backend role %q: %s", path, err)
 	}
@@ -637,11 +646,14 @@ func isEc2(authType, inferred string) bool {
 }
 
 func awsAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting AWS auth backend role %q", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("error deleting AWS auth backend role %q", path)
 	}
@@ -651,13 +663,16 @@ func awsAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if AWS auth backend role %q exists", path)
 
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if AWS auth backend role %q exists: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if AWS auth backend role %q exists", path)
@@ -7,7 +7,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 var (
@@ -53,7 +53,10 @@ func awsAuthBackendRoleTagBlacklistResource() *schema.Resource {
 }
 
 func awsAuthBackendRoleTagBlacklistWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	data := map[string]interface{}{
@@ -64,7 +67,7 @@ func awsAuthBackendRoleTagBlacklistWrite(d *schema.ResourceData, meta interface{
 	path := awsAuthBackendRoleTagBlacklistPath(backend)
 
 	log.Printf("[DEBUG] Configuring AWS auth backend roletag blacklist %q", path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 
 	if err != nil {
 		d.SetId("")
@@ -78,7 +81,10 @@ func awsAuthBackendRoleTagBlacklistWrite(d *schema.ResourceData, meta interface{
 }
 
 func awsAuthBackendRoleTagBlacklistRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -91,7 +97,7 @@ func awsAuthBackendRoleTagBlacklistRead(d *schema.ResourceData, meta interface{}
 
 	log.Printf("[DEBUG] Reading roletag blacklist %q from AWS auth backend", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("Error reading AWS auth backend roletag blacklist %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read roletag blacklist %q from AWS auth backend", path)
@@ -109,11 +115,14 @@ func awsAuthBackendRoleTagBlacklistRead(d *schema.ResourceData, meta interface{}
 }
 
 func awsAuthBackendRoleTagBlacklistDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Removing roletag blacklist %q from AWS auth backend", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("Error deleting AWS auth backend roletag blacklist %q: %s", path, err)
 	}
@@ -123,13 +132,16 @@ func awsAuthBackendRoleTagBlacklistDelete(d *schema.ResourceData, meta interface
 }
 
 func awsAuthBackendRoleTagBlacklistExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 
 	log.Printf("[DEBUG] Checking if roletag blacklist %q exists in AWS auth backend", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("Error checking for existence of AWS auth backend roletag blacklist %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if roletag blacklist %q exists in AWS auth backend", path)
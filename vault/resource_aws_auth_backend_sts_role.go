@@ -7,7 +7,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 var (
@@ -53,7 +53,10 @@ func awsAuthBackendSTSRoleResource() *schema.Resource {
 }
 
 func awsAuthBackendSTSRoleCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	accountID := d.Get("account_id").(string)
@@ -62,7 +65,7 @@ func awsAuthBackendSTSRoleCreate(d *schema.ResourceData, meta interface{}) error
 	path := awsAuthBackendSTSRolePath(backend, accountID)
 
 	log.Printf("[DEBUG] Writing STS role %q to AWS auth backend", path)
-	_, err := client.Logical().Write(path, map[string]interface{}{
+	_, err = client.Logical().Write(path, map[string]interface{}{
 		"sts_role": stsRole,
 	})
 
@@ -78,7 +81,10 @@ func awsAuthBackendSTSRoleCreate(d *schema.ResourceData, meta interface{}) error
 }
 
 func awsAuthBackendSTSRoleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -94,7 +100,7 @@ func awsAuthBackendSTSRoleRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Reading STS role %q from AWS auth backend", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading STS role %q from AWS auth backend %s", path, err)
 	}
 	log.Printf("[DEBUG] Read STS role %q from AWS auth backend", path)
@@ -111,13 +117,16 @@ func awsAuthBackendSTSRoleRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func awsAuthBackendSTSRoleUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	stsRole := d.Get("sts_role").(string)
 	path := d.Id()
 
 	log.Printf("[DEBUG] Updating STS role %q in AWS auth backend", path)
-	_, err := client.Logical().Write(path, map[string]interface{}{
+	_, err = client.Logical().Write(path, map[string]interface{}{
 		"sts_role": stsRole,
 	})
 	if err != nil {
@@ -129,11 +138,14 @@ func awsAuthBackendSTSRoleUpdate(d *schema.ResourceData, meta interface{}) error
 }
 
 func awsAuthBackendSTSRoleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Deleting STS role %q from AWS auth backend", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("error deleting STS role %q from AWS auth backend", path)
 	}
@@ -143,13 +155,16 @@ func awsAuthBackendSTSRoleDelete(d *schema.ResourceData, meta interface{}) error
 }
 
 func awsAuthBackendSTSRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if STS role %q exists in AWS auth backend", path)
 
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if STS role %q exists in AWS auth backend: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if STS role %q exists in AWS auth backend", path)
@@ -6,8 +6,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 func certAuthBackendRoleResource() *schema.Resource {
@@ -92,7 +91,10 @@ func certCertResourcePath(backend, name string) string {
 }
 
 func certAuthResourceWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	name := d.Get("name").(string)
@@ -132,7 +134,7 @@ func certAuthResourceWrite(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Writing %q to cert auth backend", path)
 	d.SetId(path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		d.SetId("")
 		return fmt.Errorf("Error writing %q to cert auth backendq: %s", path, err)
@@ -143,7 +145,10 @@ func certAuthResourceWrite(d *schema.ResourceData, meta interface{}) error {
 }
 
 func certAuthResourceUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	data := map[string]interface{}{}
@@ -179,7 +184,7 @@ func certAuthResourceUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	log.Printf("[DEBUG] Updating %q in cert auth backend", path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("Error updating %q in cert auth backend: %s", path, err)
 	}
@@ -189,12 +194,15 @@ func certAuthResourceUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func certAuthResourceRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Reading cert %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("Error reading cert %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read cert %q", path)
@@ -248,11 +256,14 @@ func certAuthResourceRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func certAuthResourceDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting cert %q", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("Error deleting cert %q", path)
 	}
@@ -6,8 +6,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 func gcpAuthBackendRoleResource() *schema.Resource {
@@ -116,7 +115,10 @@ func gcpRoleResourcePath(backend, role string) string {
 }
 
 func gcpAuthResourceWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	role := d.Get("role").(string)
@@ -171,7 +173,7 @@ func gcpAuthResourceWrite(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Writing role %q to GCP auth backend", path)
 	d.SetId(path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		d.SetId("")
 		return fmt.Errorf("Error writing GCP auth role %q: %s", path, err)
@@ -182,7 +184,10 @@ func gcpAuthResourceWrite(d *schema.ResourceData, meta interface{}) error {
 }
 
 func gcpAuthResourceUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	data := map[string]interface{}{}
@@ -224,7 +229,7 @@ func gcpAuthResourceUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	log.Printf("[DEBUG] Updating role %q in GCP auth backend", path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("Error updating GCP auth role %q: %s", path, err)
 	}
@@ -234,12 +239,15 @@ func gcpAuthResourceUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func gcpAuthResourceRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Reading GCP role %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("Error reading GCP role %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read GCP role %q", path)
@@ -292,11 +300,14 @@ func gcpAuthResourceRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func gcpAuthResourceDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting GCP role %q", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("Error deleting GCP role %q", path)
 	}
@@ -73,7 +73,10 @@ func gcpSecretBackendResource() *schema.Resource {
 }
 
 func gcpSecretBackendCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Get("path").(string)
 	description := d.Get("description").(string)
@@ -85,7 +88,7 @@ func gcpSecretBackendCreate(d *schema.ResourceData, meta interface{}) error {
 
 	d.Partial(true)
 	log.Printf("[DEBUG] Mounting GCP backend at %q", path)
-	err := client.Sys().Mount(path, &api.MountInput{
+	err = client.Sys().Mount(path, &api.MountInput{
 		Type:        "gcp",
 		Description: description,
 		Config: api.MountConfigInput{
@@ -122,7 +125,10 @@ func gcpSecretBackendCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func gcpSecretBackendRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -151,7 +157,10 @@ func gcpSecretBackendRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func gcpSecretBackendUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	d.Partial(true)
@@ -175,12 +184,15 @@ func gcpSecretBackendUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func gcpSecretBackendDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
 	log.Printf("[DEBUG] Unmounting GCP backend %q", path)
-	err := client.Sys().Unmount(path)
+	err = client.Sys().Unmount(path)
 	if err != nil {
 		return fmt.Errorf("error unmounting GCP backend from %q: %s", path, err)
 	}
@@ -189,7 +201,10 @@ func gcpSecretBackendDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func gcpSecretBackendExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if GCP backend exists at %q", path)
 	mounts, err := client.Sys().ListMounts()
@@ -4,13 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 const latestSecretVersion = -1
 
+// genericSecretMergeAttempts is the number of times a partially owned KV
+// version 2 secret is read and written again when another client changed it
+// in between.
+const genericSecretMergeAttempts = 5
+
 func genericSecretResource() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
@@ -46,11 +53,12 @@ func genericSecretResource() *schema.Resource {
 				ValidateFunc: ValidateDataJSON,
 				Sensitive:    true,
 			},
-                        "data": &schema.Schema{
+			"data": &schema.Schema{
 				Type:        schema.TypeMap,
 				Required:    false,
 				Description: "Data returned from the resource.  Should be a map containing the content from data_json.",
 				Computed:    true,
+				Sensitive:   true,
 			},
 			"allow_read": {
 				Type:        schema.TypeBool,
@@ -71,6 +79,36 @@ func genericSecretResource() *schema.Resource {
 				Default:     false,
 				Description: "If set to true, will disable read and capture the response to the write in the data object.",
 			},
+			"partial_ownership": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				Description:   "Only manage the keys in data_json, leaving any other keys in the secret as they are.",
+				ConflictsWith: []string{"disable_read", "allow_read", "capture_response"},
+			},
+			"generator": genericSecretGeneratorSchema(),
+			"rotation_triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Arbitrary map of values that, when changed, generates the values of the generator blocks again.",
+			},
+			"rotate_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: util.ValidateDuration,
+				Description:  "Duration, such as 720h, after which the values of the generator blocks are generated again.",
+			},
+			"generated_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time at which the values of the generator blocks were last generated.",
+			},
+			"audit_hash_path": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Path of an audit device used to hash the secret's values, so that only their HMACs are stored in the state.",
+				ConflictsWith: []string{"disable_read", "allow_read", "capture_response"},
+			},
 		},
 	}
 }
@@ -107,21 +145,139 @@ func NormalizeDataJSON(configI interface{}) string {
 }
 
 func genericSecretResourceWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
-	var data map[string]interface{}
-	err := json.Unmarshal([]byte(d.Get("data_json").(string)), &data)
+	generators, err := genericSecretGenerators(d)
 	if err != nil {
-		return fmt.Errorf("data_json %#v syntax error: %s", d.Get("data_json"), err)
+		return err
+	}
+	rotate := len(generators) > 0 && genericSecretRotationDue(d)
+
+	// When hashing, data_json only holds the values from the configuration
+	// if they changed. Otherwise it holds their hashes, which mustn't be
+	// written.
+	hashedData := d.Get("audit_hash_path").(string) != "" && !d.IsNewResource() && !d.HasChange("data_json")
+	if hashedData && len(generators) == 0 {
+		return genericSecretResourceRead(d, meta)
+	}
+
+	var data map[string]interface{}
+	if !hashedData {
+		err = json.Unmarshal([]byte(d.Get("data_json").(string)), &data)
+		if err != nil {
+			return fmt.Errorf("data_json %#v syntax error: %s", d.Get("data_json"), err)
+		}
+		for key := range generators {
+			if _, ok := data[key]; ok {
+				return fmt.Errorf("%q is both in data_json and generated", key)
+			}
+		}
 	}
 
 	path := d.Get("path").(string)
 	originalPath := path // if the path belongs to a v2 endpoint, it will be modified
-	mountPath, v2, err := isKVv2(path, client)
+	mountPath, v2, err := isKVv2(path, client, meta.(*ProviderMeta).ServerVersion())
 	if err != nil {
 		return fmt.Errorf("error determining if it's a v2 path: %s", err)
 	}
 
+	// Generated values are only kept in Vault, so they're read back to be
+	// written again unchanged, as are the unchanged values of data_json
+	// when it holds hashes.
+	var currentData map[string]interface{}
+	if hashedData || (len(generators) > 0 && !rotate) {
+		log.Printf("[DEBUG] Reading %s from Vault to keep its current values", originalPath)
+		current, err := versionedSecret(latestSecretVersion, originalPath, client, meta.(*ProviderMeta).ServerVersion())
+		if err != nil {
+			return fmt.Errorf("error reading from Vault: %s", err)
+		}
+		if current != nil {
+			currentData = current.Data
+		}
+
+		if hashedData {
+			keys, err := genericSecretKeys(d.Get("data_json").(string))
+			if err != nil {
+				return err
+			}
+			data = map[string]interface{}{}
+			for _, k := range keys {
+				if v, ok := currentData[k]; ok {
+					data[k] = v
+				}
+			}
+		}
+	}
+
+	regenerate := genericSecretRegenerate(d, generators, currentData, rotate)
+	if hashedData && len(regenerate) == 0 {
+		return genericSecretResourceRead(d, meta)
+	}
+	for key, g := range generators {
+		if !regenerate[key] {
+			data[key] = currentData[key]
+			continue
+		}
+		log.Printf("[DEBUG] Generating the value of %q in %s", key, originalPath)
+		value, err := generateSecretValue(client, g)
+		if err != nil {
+			return err
+		}
+		data[key] = value
+	}
+	generatedTime := d.Get("generated_time").(string)
+	if rotate || (len(generators) > 0 && generatedTime == "") {
+		generatedTime = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	// Make sure the values can be hashed before writing them, rather than
+	// failing to read them back.
+	if auditPath := d.Get("audit_hash_path").(string); auditPath != "" && d.IsNewResource() {
+		if _, err := auditHash(meta.(*ProviderMeta).GetClient(), auditPath, ""); err != nil {
+			return err
+		}
+	}
+
+	if d.Get("partial_ownership").(bool) {
+		// Keys that were managed before, but have since been removed from
+		// data_json, are removed from the secret. Nothing is removed when
+		// the resource has just taken partial ownership, since it didn't
+		// know which keys it owned.
+		var removed []string
+		if wasPartial, _ := d.GetChange("partial_ownership"); wasPartial.(bool) {
+			oldJSON, _ := d.GetChange("data_json")
+			oldGenerators, _ := d.GetChange("generator")
+			oldKeys, err := genericSecretOwnedKeys(oldJSON.(string), oldGenerators.([]interface{}))
+			if err != nil {
+				return err
+			}
+			for _, k := range oldKeys {
+				if _, ok := data[k]; !ok {
+					removed = append(removed, k)
+				}
+			}
+		}
+
+		err := genericSecretMerge(client, path, mountPath, v2, func(current map[string]interface{}) {
+			for _, k := range removed {
+				delete(current, k)
+			}
+			for k, v := range data {
+				current[k] = v
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		d.SetId(originalPath)
+		d.Set("generated_time", generatedTime)
+		return genericSecretResourceRead(d, meta)
+	}
+
 	if v2 {
 		path = addPrefixToVKVPath(path, mountPath, "data")
 		data = map[string]interface{}{
@@ -138,6 +294,9 @@ func genericSecretResourceWrite(d *schema.ResourceData, meta interface{}) error
 	}
 
 	d.SetId(originalPath)
+	if len(generators) > 0 {
+		d.Set("generated_time", generatedTime)
+	}
 	captureResponse := d.Get("capture_response").(bool)
 	if captureResponse {
 		log.Printf("[DEBUG] Capture response is set.")
@@ -151,23 +310,7 @@ func genericSecretResourceWrite(d *schema.ResourceData, meta interface{}) error
 		}
 		d.Set("data_json", string(jsonDataBytes))
 
-		// Since our "data" map can only contain string values, we
-		// will take strings from Data and write them in as-is,
-		// and write everything else in as a JSON serialization of
-		// whatever value we get so that complex types can be
-		// passed around and processed elsewhere if desired.
-		dataMap := map[string]string{}
-		for k, v := range secret.Data {
-			if vs, ok := v.(string); ok {
-				dataMap[k] = vs
-				continue
-			}
-			// Again ignoring error because we know this value
-			// came from JSON in the first place and so must be valid.
-			vBytes, _ := json.Marshal(v)
-			dataMap[k] = string(vBytes)
-		}
-		d.Set("data", dataMap)
+		d.Set("data", serializeDataMapToString(secret.Data))
 
 		d.Set("path", path)
 		return nil
@@ -177,15 +320,30 @@ func genericSecretResourceWrite(d *schema.ResourceData, meta interface{}) error
 }
 
 func genericSecretResourceDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
-	mountPath, v2, err := isKVv2(path, client)
+	mountPath, v2, err := isKVv2(path, client, meta.(*ProviderMeta).ServerVersion())
 	if err != nil {
 		return fmt.Errorf("error determining if it's a v2 path: %s", err)
 	}
 
+	if d.Get("partial_ownership").(bool) {
+		keys, err := genericSecretOwnedKeys(d.Get("data_json").(string), d.Get("generator").([]interface{}))
+		if err != nil {
+			return err
+		}
+		return genericSecretMerge(client, path, mountPath, v2, func(current map[string]interface{}) {
+			for _, k := range keys {
+				delete(current, k)
+			}
+		})
+	}
+
 	if v2 {
 		path = addPrefixToVKVPath(path, mountPath, "data")
 	}
@@ -210,10 +368,13 @@ func genericSecretResourceRead(d *schema.ResourceData, meta interface{}) error {
 	path := d.Id()
 
 	if shouldRead {
-		client := meta.(*api.Client)
+		client, err := getClient(d, meta)
+		if err != nil {
+			return err
+		}
 
 		log.Printf("[DEBUG] Reading %s from Vault", path)
-		secret, err := versionedSecret(latestSecretVersion, path, client)
+		secret, err := versionedSecret(latestSecretVersion, path, client, meta.(*ProviderMeta).ServerVersion())
 
 		if err != nil {
 			return fmt.Errorf("error reading from Vault: %s", err)
@@ -224,14 +385,77 @@ func genericSecretResourceRead(d *schema.ResourceData, meta interface{}) error {
 			return nil
 		}
 
-		log.Printf("[DEBUG] secret: %#v", secret)
+		generators, err := genericSecretGenerators(d)
+		if err != nil {
+			return err
+		}
+
+		if d.Get("partial_ownership").(bool) {
+			// Only the keys the resource owns are compared, and stored.
+			keys, err := genericSecretOwnedKeys(d.Get("data_json").(string), d.Get("generator").([]interface{}))
+			if err != nil {
+				return err
+			}
+			owned := map[string]interface{}{}
+			for _, k := range keys {
+				if v, ok := secret.Data[k]; ok {
+					owned[k] = v
+				}
+			}
+			secret.Data = owned
+		}
+
+		if auditPath := d.Get("audit_hash_path").(string); auditPath != "" {
+			hashed, err := auditHashValues(meta.(*ProviderMeta).GetClient(), auditPath, secret.Data)
+			if err != nil {
+				return err
+			}
+			secret.Data = hashed
+		}
+
+		// Generated values aren't in the configuration, so they're left out
+		// of data_json to avoid a diff.
+		configured := map[string]interface{}{}
+		for k, v := range secret.Data {
+			if _, ok := generators[k]; !ok {
+				configured[k] = v
+			}
+		}
+
+		if len(generators) > 0 {
+			due, err := genericSecretRotateAfterPassed(d.Get("generated_time").(string), d.Get("rotate_after").(string))
+			if err != nil {
+				return err
+			}
+			if due {
+				log.Printf("[DEBUG] generated values in %s are due to be generated again", path)
+				d.Set("generated_time", "")
+			}
+
+			// A generator whose value is missing is dropped from the state,
+			// so that the plan adds it back and only its value is generated
+			// again.
+			var present []interface{}
+			for _, g := range d.Get("generator").([]interface{}) {
+				key := g.(map[string]interface{})["key"].(string)
+				if _, ok := secret.Data[key]; !ok {
+					log.Printf("[WARN] generated %q not found in %s, it will be generated again", key, path)
+					continue
+				}
+				present = append(present, g)
+			}
+			if len(present) < len(generators) {
+				d.Set("generator", present)
+			}
+		}
 
-		jsonData, err := json.Marshal(secret.Data)
+		jsonData, err := json.Marshal(configured)
 		if err != nil {
 			return fmt.Errorf("error marshaling JSON for %q: %s", path, err)
 		}
 
 		d.Set("data_json", string(jsonData))
+		d.Set("data", serializeDataMapToString(secret.Data))
 		d.Set("path", path)
 	} else {
 		log.Printf("[WARN] vault_generic_secret does not refresh when disable_read is set to true")
@@ -239,3 +463,127 @@ func genericSecretResourceRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("disable_read", !shouldRead)
 	return nil
 }
+
+// genericSecretKeys returns the keys of the JSON object in dataJSON, which
+// may be empty.
+func genericSecretKeys(dataJSON string) ([]string, error) {
+	if dataJSON == "" {
+		return nil, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return nil, fmt.Errorf("data_json %#v syntax error: %s", dataJSON, err)
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// genericSecretOwnedKeys returns the keys that a partially owned secret
+// resource manages: those in dataJSON and those of its generator blocks.
+func genericSecretOwnedKeys(dataJSON string, generators []interface{}) ([]string, error) {
+	keys, err := genericSecretKeys(dataJSON)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range generators {
+		keys = append(keys, g.(map[string]interface{})["key"].(string))
+	}
+	return keys, nil
+}
+
+// genericSecretMerge reads the secret at path, changes its data with update
+// and writes it back, deleting it if no keys are left. KV version 2 secrets
+// are written with check-and-set, and merged again if another client wrote
+// to the secret in between.
+func genericSecretMerge(client *api.Client, path, mountPath string, v2 bool, update func(map[string]interface{})) error {
+	if v2 {
+		path = addPrefixToVKVPath(path, mountPath, "data")
+	}
+
+	for attempt := 1; ; attempt++ {
+		log.Printf("[DEBUG] Reading %s from Vault to merge keys", path)
+		secret, err := kvReadRequest(client, path, nil)
+		if err != nil {
+			return fmt.Errorf("error reading from Vault: %s", err)
+		}
+
+		data := map[string]interface{}{}
+		version := 0
+		if secret != nil {
+			current := secret.Data
+			if v2 {
+				current, _ = secret.Data["data"].(map[string]interface{})
+				// A deleted latest version still counts for check-and-set.
+				metadata, _ := secret.Data["metadata"].(map[string]interface{})
+				if v, ok := metadata["version"].(json.Number); ok {
+					n, err := v.Int64()
+					if err != nil {
+						return fmt.Errorf("unexpected version %q for %q: %s", v, path, err)
+					}
+					version = int(n)
+				}
+			}
+			for k, v := range current {
+				data[k] = v
+			}
+		}
+		existed := len(data) > 0
+
+		update(data)
+
+		if len(data) == 0 {
+			if !existed {
+				return nil
+			}
+			log.Printf("[DEBUG] No keys left in %s, deleting it", path)
+			if _, err := client.Logical().Delete(path); err != nil {
+				return fmt.Errorf("error deleting %q from Vault: %s", path, err)
+			}
+			return nil
+		}
+
+		body := data
+		if v2 {
+			body = map[string]interface{}{
+				"data":    data,
+				"options": map[string]interface{}{"cas": version},
+			}
+		}
+
+		log.Printf("[DEBUG] Writing merged generic Vault secret to %s", path)
+		_, err = client.Logical().Write(path, body)
+		if v2 && util.IsCheckAndSetMismatch(err) && attempt < genericSecretMergeAttempts {
+			log.Printf("[DEBUG] %s changed while merging keys, trying again", path)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error writing to Vault: %s", err)
+		}
+		return nil
+	}
+}
+
+// serializeDataMapToString converts secret data into a map that can be
+// stored in a TypeMap attribute.
+func serializeDataMapToString(data map[string]interface{}) map[string]string {
+	// Since our "data" map can only contain string values, we
+	// will take strings from Data and write them in as-is,
+	// and write everything else in as a JSON serialization of
+	// whatever value we get so that complex types can be
+	// passed around and processed elsewhere if desired.
+	dataMap := map[string]string{}
+	for k, v := range data {
+		if vs, ok := v.(string); ok {
+			dataMap[k] = vs
+			continue
+		}
+		// Again ignoring error because we know this value
+		// came from JSON in the first place and so must be valid.
+		vBytes, _ := json.Marshal(v)
+		dataMap[k] = string(vBytes)
+	}
+	return dataMap
+}
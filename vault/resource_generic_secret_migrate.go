@@ -24,13 +24,10 @@ func resourceGenericSecretMigrateState(v int, s *terraform.InstanceState, meta i
 }
 
 func migrateGenericSecretStateV0toV1(s *terraform.InstanceState) (*terraform.InstanceState, error) {
-	log.Printf("[DEBUG] Attributes before migration: %#v", s.Attributes)
-
 	disabledRead := s.Attributes["allow_read"] != "true"
 	if disabledRead {
 		s.Attributes["disable_read"] = "true"
 	}
 
-	log.Printf("[DEBUG] Attributes after migration: %#v:", s.Attributes)
 	return s, nil
 }
@@ -2,12 +2,15 @@ package vault
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/hashicorp/vault/api"
 )
 
 func TestResourceGenericSecret(t *testing.T) {
@@ -40,7 +43,7 @@ func TestResourceGenericSecret_deleted(t *testing.T) {
 			},
 			{
 				PreConfig: func() {
-					client := testProvider.Meta().(*api.Client)
+					client := testProvider.Meta().(*ProviderMeta).GetClient()
 					_, err := client.Logical().Delete(path)
 					if err != nil {
 						t.Fatalf("unable to manually delete the secret via the SDK: %s", err)
@@ -95,7 +98,7 @@ func testResourceGenericSecret_initialCheck(expectedPath string) resource.TestCh
 			return fmt.Errorf("unexpected secret path")
 		}
 
-		client := testProvider.Meta().(*api.Client)
+		client := testProvider.Meta().(*ProviderMeta).GetClient()
 		secret, err := client.Logical().Read(path)
 		if err != nil {
 			return fmt.Errorf("error reading back secret: %s", err)
@@ -137,7 +140,7 @@ func testResourceGenericSecret_updateCheck(s *terraform.State) error {
 
 	path := instanceState.ID
 
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 	secret, err := client.Logical().Read(path)
 	if err != nil {
 		return fmt.Errorf("error reading back secret: %s", err)
@@ -149,3 +152,765 @@ func testResourceGenericSecret_updateCheck(s *terraform.State) error {
 
 	return nil
 }
+
+func TestResourceGenericSecret_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(s *terraform.State) error {
+			if f.Read("secretsv1/foo") != nil {
+				return fmt.Errorf("secret still exists")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_initialConfig("secretsv1/test"),
+				Check:  testResourceGenericSecret_fakeVaultCheck(f, "secretsv1/test", "zap"),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_updateConfig,
+				Check:  testResourceGenericSecret_fakeVaultCheck(f, "secretsv1/foo", "zoop"),
+			},
+			{
+				// Changes made outside of Terraform are detected and undone.
+				PreConfig: func() {
+					f.Write("secretsv1/foo", map[string]interface{}{"zip": "drifted"})
+				},
+				Config: f.ProviderConfig() + testResourceGenericSecret_updateConfig,
+				Check:  testResourceGenericSecret_fakeVaultCheck(f, "secretsv1/foo", "zoop"),
+			},
+		},
+	})
+}
+
+func TestResourceGenericSecret_fakeVaultV2(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_v2Config("zap"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_generic_secret.test", "path", "secret/foo"),
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zap"),
+				),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_v2Config("zoop"),
+				Check:  testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zoop"),
+			},
+			{
+				// Writes that fail with a server error are retried.
+				PreConfig: func() {
+					f.InjectFault(fakeVaultFault{Method: "PUT", Path: "secret/data/foo", Status: 500, Count: 2})
+				},
+				Config: f.ProviderConfig() + testResourceGenericSecret_v2Config("zup"),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zup"),
+					func(s *terraform.State) error {
+						if got, want := f.RequestCount("PUT", "secret/data/foo"), 5; got != want {
+							return fmt.Errorf("expected %d writes, got %d", want, got)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceGenericSecret_fakeVaultFaults(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("secretsv1", "kv", map[string]string{"version": "1"})
+
+	tests := []struct {
+		name      string
+		fault     *fakeVaultFault
+		seal      bool
+		wantErr   string
+		wantState bool
+	}{
+		{
+			name:      "ok",
+			wantState: true,
+		},
+		{
+			name:      "permission denied",
+			fault:     &fakeVaultFault{Method: "GET", Path: "secretsv1/foo", Status: 403},
+			wantErr:   "permission denied",
+			wantState: true,
+		},
+		{
+			name:      "preflight permission denied",
+			fault:     &fakeVaultFault{Path: "sys/internal/ui/mounts/", Status: 403},
+			wantErr:   "preflight capability check returned 403",
+			wantState: true,
+		},
+		{
+			name:      "server error",
+			fault:     &fakeVaultFault{Method: "GET", Path: "secretsv1/foo", Status: 500},
+			wantErr:   "Code: 500",
+			wantState: true,
+		},
+		{
+			name:      "sealed",
+			seal:      true,
+			wantErr:   "Vault is sealed",
+			wantState: true,
+		},
+		{
+			name:  "not found",
+			fault: &fakeVaultFault{Method: "GET", Path: "secretsv1/foo", Status: 404},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.Write("secretsv1/foo", map[string]interface{}{"zip": "zap"})
+			f.ClearFaults()
+			f.Unseal()
+			if tt.fault != nil {
+				f.InjectFault(*tt.fault)
+			}
+			if tt.seal {
+				f.Seal()
+			}
+
+			d := genericSecretResource().TestResourceData()
+			d.SetId("secretsv1/foo")
+
+			err := genericSecretResourceRead(d, f.Meta())
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+			if got := d.Id() != ""; got != tt.wantState {
+				t.Fatalf("expected the secret to be in state: %t, got %t", tt.wantState, got)
+			}
+		})
+	}
+}
+
+func testResourceGenericSecret_v2Config(value string) string {
+	return fmt.Sprintf(`
+resource "vault_generic_secret" "test" {
+    path = "secret/foo"
+    data_json = <<EOT
+{
+    "zip": %q
+}
+EOT
+}`, value)
+}
+
+func testResourceGenericSecret_fakeVaultCheck(f *fakeVault, path, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		data := f.Read(path)
+		if data == nil {
+			return fmt.Errorf("secret %q not found", path)
+		}
+		if nested, ok := data["data"].(map[string]interface{}); ok {
+			data = nested
+		}
+		if got := data["zip"]; got != want {
+			return fmt.Errorf("'zip' data is %q; want %q", got, want)
+		}
+		return nil
+	}
+}
+
+func testResourceGenericSecret_partialConfig(path, dataJSON string) string {
+	return fmt.Sprintf(`
+resource "vault_generic_secret" "test" {
+    path              = %q
+    partial_ownership = true
+    data_json         = <<EOT
+%s
+EOT
+}`, path, dataJSON)
+}
+
+func testResourceGenericSecret_fakeVaultData(f *fakeVault, path string, want map[string]interface{}) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		data := f.Read(path)
+		if nested, ok := data["data"].(map[string]interface{}); ok {
+			data = nested
+		}
+		if got, want := fmt.Sprint(data), fmt.Sprint(want); got != want {
+			return fmt.Errorf("bad data in %q: want %s, got %s", path, want, got)
+		}
+		return nil
+	}
+}
+
+func TestResourceGenericSecret_fakeVaultPartial(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		t.Run(fmt.Sprintf("v2=%t", v2), func(t *testing.T) {
+			f := newFakeVault(t)
+			path, dataPath := "secretsv1/foo", "secretsv1/foo"
+			write := func(data map[string]interface{}) {
+				f.Write(dataPath, data)
+			}
+			if v2 {
+				path, dataPath = "secret/foo", "secret/data/foo"
+				write = func(data map[string]interface{}) {
+					f.Write(dataPath, map[string]interface{}{"data": data})
+				}
+			} else {
+				f.Mount("secretsv1", "kv", map[string]string{"version": "1"})
+			}
+			write(map[string]interface{}{"rotated": "by-job", "zip": "old"})
+
+			resource.UnitTest(t, resource.TestCase{
+				Providers: f.Providers(),
+				// Only the resource's own keys are removed.
+				CheckDestroy: testResourceGenericSecret_fakeVaultData(f, dataPath, map[string]interface{}{"rotated": "by-job-again"}),
+				Steps: []resource.TestStep{
+					{
+						Config: f.ProviderConfig() + testResourceGenericSecret_partialConfig(path, `{"zip": "zap", "zoo": "zoom"}`),
+						Check: resource.ComposeTestCheckFunc(
+							testResourceGenericSecret_fakeVaultData(f, dataPath, map[string]interface{}{"rotated": "by-job", "zip": "zap", "zoo": "zoom"}),
+							resource.TestCheckResourceAttr("vault_generic_secret.test", "data.%", "2"),
+							resource.TestCheckResourceAttr("vault_generic_secret.test", "data.zip", "zap"),
+							resource.TestCheckNoResourceAttr("vault_generic_secret.test", "data.rotated"),
+						),
+					},
+					{
+						// Changes to other keys aren't drift.
+						PreConfig: func() {
+							write(map[string]interface{}{"rotated": "by-job-again", "zip": "zap", "zoo": "zoom"})
+						},
+						Config:   f.ProviderConfig() + testResourceGenericSecret_partialConfig(path, `{"zip": "zap", "zoo": "zoom"}`),
+						PlanOnly: true,
+					},
+					{
+						// Changes to the resource's keys are undone.
+						PreConfig: func() {
+							write(map[string]interface{}{"rotated": "by-job-again", "zip": "drifted", "zoo": "zoom"})
+						},
+						Config: f.ProviderConfig() + testResourceGenericSecret_partialConfig(path, `{"zip": "zap", "zoo": "zoom"}`),
+						Check:  testResourceGenericSecret_fakeVaultData(f, dataPath, map[string]interface{}{"rotated": "by-job-again", "zip": "zap", "zoo": "zoom"}),
+					},
+					{
+						// Keys removed from the configuration are removed from
+						// the secret.
+						Config: f.ProviderConfig() + testResourceGenericSecret_partialConfig(path, `{"zip": "zap"}`),
+						Check:  testResourceGenericSecret_fakeVaultData(f, dataPath, map[string]interface{}{"rotated": "by-job-again", "zip": "zap"}),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestResourceGenericSecret_fakeVaultPartialCAS(t *testing.T) {
+	f := newFakeVault(t)
+	f.Write("secret/data/foo", map[string]interface{}{
+		"data": map[string]interface{}{"rotated": "by-job"},
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		// The secret is deleted once no keys are left.
+		CheckDestroy: func(s *terraform.State) error {
+			if f.Read("secret/data/foo") != nil {
+				return fmt.Errorf("secret still exists")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				// Another client wrote to the secret between the read and
+				// the write.
+				PreConfig: func() {
+					f.InjectFault(fakeVaultFault{
+						Method: "PUT",
+						Path:   "secret/data/foo",
+						Status: 400,
+						Errors: []string{"check-and-set parameter did not match the current version"},
+						Count:  1,
+					})
+				},
+				Config: f.ProviderConfig() + testResourceGenericSecret_partialConfig("secret/foo", `{"zip": "zap"}`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultData(f, "secret/data/foo", map[string]interface{}{"rotated": "by-job", "zip": "zap"}),
+					func(s *terraform.State) error {
+						if got, want := f.RequestCount("PUT", "secret/data/foo"), 2; got != want {
+							return fmt.Errorf("expected %d writes, got %d", want, got)
+						}
+						for _, r := range f.Requests() {
+							if r.Method != "PUT" || r.Path != "secret/data/foo" {
+								continue
+							}
+							options, _ := r.Body["options"].(map[string]interface{})
+							if got := fmt.Sprint(options["cas"]); got != "1" {
+								return fmt.Errorf("expected writes with cas 1, got %s", got)
+							}
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// The other client's key goes away.
+				PreConfig: func() {
+					f.Write("secret/data/foo", map[string]interface{}{
+						"data": map[string]interface{}{"zip": "zap"},
+					})
+				},
+				Config: f.ProviderConfig() + testResourceGenericSecret_partialConfig("secret/foo", `{"zip": "zap"}`),
+			},
+		},
+	})
+}
+
+func TestResourceGenericSecret_partialConflicts(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_generic_secret" "test" {
+    path              = "secret/foo"
+    partial_ownership = true
+    disable_read      = true
+    data_json         = "{}"
+}`,
+				ExpectError: regexp.MustCompile(`partial_ownership.*conflicts with disable_read`),
+			},
+		},
+	})
+}
+
+func testResourceGenericSecret_hashConfig(value string, partial bool) string {
+	return fmt.Sprintf(`
+resource "vault_generic_secret" "test" {
+    path              = "secret/foo"
+    audit_hash_path   = "hash"
+    partial_ownership = %t
+    data_json         = <<EOT
+{
+    "zip": %q,
+    "count": 3
+}
+EOT
+}`, partial, value)
+}
+
+// testResourceGenericSecret_noPlaintext fails if any attribute in the state
+// holds one of the values.
+func testResourceGenericSecret_noPlaintext(values ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs := s.RootModule().Resources["vault_generic_secret.test"]
+		if rs == nil {
+			return fmt.Errorf("resource not found in state")
+		}
+		for k, v := range rs.Primary.Attributes {
+			for _, value := range values {
+				if strings.Contains(v, value) {
+					return fmt.Errorf("attribute %q holds %q: %s", k, value, v)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func TestResourceGenericSecret_fakeVaultAuditHash(t *testing.T) {
+	f := newFakeVault(t)
+	f.Write("sys/audit/hash", map[string]interface{}{"type": "file"})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_hashConfig("zap", false),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zap"),
+					testResourceGenericSecret_noPlaintext("zap"),
+					resource.TestMatchResourceAttr("vault_generic_secret.test", "data.zip", regexp.MustCompile(`^hmac-sha256:`)),
+					resource.TestMatchResourceAttr("vault_generic_secret.test", "data.count", regexp.MustCompile(`^hmac-sha256:`)),
+				),
+			},
+			{
+				// Unchanged values have the same hashes.
+				Config:   f.ProviderConfig() + testResourceGenericSecret_hashConfig("zap", false),
+				PlanOnly: true,
+			},
+			{
+				// Changes made outside of Terraform are detected and undone.
+				PreConfig: func() {
+					f.Write("secret/data/foo", map[string]interface{}{
+						"data": map[string]interface{}{"zip": "drifted", "count": 3},
+					})
+				},
+				Config: f.ProviderConfig() + testResourceGenericSecret_hashConfig("zap", false),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zap"),
+					testResourceGenericSecret_noPlaintext("zap", "drifted"),
+				),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_hashConfig("zoop", false),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zoop"),
+					testResourceGenericSecret_noPlaintext("zoop"),
+				),
+			},
+			{
+				// Other changes don't write the hashes to Vault.
+				Config: f.ProviderConfig() + testResourceGenericSecret_hashConfig("zoop", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_generic_secret.test", "partial_ownership", "true"),
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zoop"),
+					testResourceGenericSecret_noPlaintext("zoop"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceGenericSecret_fakeVaultAuditHashMissingDevice(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config:      f.ProviderConfig() + testResourceGenericSecret_hashConfig("zap", false),
+				ExpectError: regexp.MustCompile(`error hashing with "sys/audit-hash/hash"`),
+			},
+		},
+	})
+}
+
+func testResourceGenericSecret_generatorConfig(value, trigger, generator string, extra ...string) string {
+	return fmt.Sprintf(`
+resource "vault_generic_secret" "test" {
+    path      = "secret/foo"
+    data_json = <<EOT
+{
+    "zip": %q
+}
+EOT
+
+    rotation_triggers = {
+        trigger = %q
+    }
+
+    generator {
+        key = "password"
+        %s
+    }
+    %s
+}`, value, trigger, generator, strings.Join(extra, "\n    "))
+}
+
+// testResourceGenericSecret_fakeVaultValue saves the value of a key of the
+// secret at path in *value.
+func testResourceGenericSecret_fakeVaultValue(f *fakeVault, path, key string, value *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		data := f.Read(path)
+		if nested, ok := data["data"].(map[string]interface{}); ok {
+			data = nested
+		}
+		v, ok := data[key].(string)
+		if !ok {
+			return fmt.Errorf("%q not found in %q", key, path)
+		}
+		*value = v
+		return nil
+	}
+}
+
+func TestResourceGenericSecret_fakeVaultGenerator(t *testing.T) {
+	f := newFakeVault(t)
+
+	var first, second, third, fourth string
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "1", `length = 20`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zap"),
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &first),
+					func(s *terraform.State) error {
+						if !regexp.MustCompile(`^[a-zA-Z0-9]{20}$`).MatchString(first) {
+							return fmt.Errorf("unexpected generated value %q", first)
+						}
+						return nil
+					},
+					resource.TestCheckResourceAttr("vault_generic_secret.test", "data_json", `{"zip":"zap"}`),
+					resource.TestCheckResourceAttrPtr("vault_generic_secret.test", "data.password", &first),
+					resource.TestCheckResourceAttrSet("vault_generic_secret.test", "generated_time"),
+				),
+			},
+			{
+				// Other changes keep the generated value.
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zoop", "1", `length = 20`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zoop"),
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &second),
+					func(s *terraform.State) error {
+						if second != first {
+							return fmt.Errorf("generated value changed from %q to %q", first, second)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zoop", "2", `length = 20`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &third),
+					func(s *terraform.State) error {
+						if third == second {
+							return fmt.Errorf("generated value %q wasn't rotated", third)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// A generated value that goes missing is generated again.
+				PreConfig: func() {
+					f.Write("secret/data/foo", map[string]interface{}{
+						"data": map[string]interface{}{"zip": "zoop"},
+					})
+				},
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zoop", "2", `length = 20`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &fourth),
+					func(s *terraform.State) error {
+						if fourth == third {
+							return fmt.Errorf("generated value %q wasn't generated again", fourth)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceGenericSecret_fakeVaultGeneratorChange(t *testing.T) {
+	f := newFakeVault(t)
+
+	var password, apiKey, secondPassword, secondAPIKey string
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "1", "", `generator {
+        key    = "api_key"
+        length = 16
+    }`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &password),
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "api_key", &apiKey),
+				),
+			},
+			{
+				// Only the value of the changed generator is generated again.
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "1", "", `generator {
+        key    = "api_key"
+        length = 24
+    }`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &secondPassword),
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "api_key", &secondAPIKey),
+					func(s *terraform.State) error {
+						if secondPassword != password {
+							return fmt.Errorf("generated value changed from %q to %q", password, secondPassword)
+						}
+						if len(secondAPIKey) != 24 {
+							return fmt.Errorf("generated value %q wasn't generated again", secondAPIKey)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceGenericSecret_fakeVaultGeneratorImport(t *testing.T) {
+	f := newFakeVault(t)
+	f.Write("secret/data/foo", map[string]interface{}{
+		"data": map[string]interface{}{"zip": "zap", "password": "imported"},
+	})
+
+	p := Provider().(*vaultProvider)
+	providerConfig, err := config.NewRawConfig(map[string]interface{}{
+		"address":     f.Address(),
+		"token":       fakeVaultRootToken,
+		"max_retries": 0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Configure(terraform.NewResourceConfig(providerConfig)); err != nil {
+		t.Fatal(err)
+	}
+	resourceConfig, err := config.NewRawConfig(map[string]interface{}{
+		"path":      "secret/foo",
+		"data_json": `{"zip": "zap"}`,
+		"generator": []interface{}{
+			map[string]interface{}{"key": "password"},
+		},
+		"rotation_triggers": map[string]interface{}{"trigger": "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := &terraform.InstanceInfo{Type: "vault_generic_secret"}
+	imported, err := p.ImportState(info, "secret/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := p.Refresh(info, imported[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first plan only records the configuration, which can't be read
+	// back from Vault, and keeps the imported values.
+	diff, err := p.Diff(info, state, terraform.NewResourceConfig(resourceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attr, ok := diff.GetAttribute("generated_time"); ok && attr.NewComputed {
+		t.Fatalf("expected the imported values to be kept, got a plan to generate them: %#v", diff)
+	}
+	state, err = p.Apply(info, state, diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var password string
+	if err := testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &password)(nil); err != nil {
+		t.Fatal(err)
+	}
+	if password != "imported" {
+		t.Fatalf("expected the imported value to be kept, got %q", password)
+	}
+	if state.Attributes["generated_time"] == "" {
+		t.Fatal("expected generated_time to be set")
+	}
+
+	state, err = p.Refresh(info, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err = p.Diff(info, state, terraform.NewResourceConfig(resourceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected no changes after the import was applied, got %#v", diff)
+	}
+}
+
+func TestResourceGenericSecret_fakeVaultGeneratorRotateAfter(t *testing.T) {
+	f := newFakeVault(t)
+
+	var first, second string
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "1", "", `rotate_after = "2s"`),
+				Check:  testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &first),
+			},
+			{
+				PreConfig: func() {
+					time.Sleep(2 * time.Second)
+				},
+				Config:             f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "1", "", `rotate_after = "2s"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "1", "", `rotate_after = "2s"`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zap"),
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &second),
+					func(s *terraform.State) error {
+						if second == first {
+							return fmt.Errorf("generated value %q wasn't rotated", second)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceGenericSecret_fakeVaultGeneratorPolicy(t *testing.T) {
+	f := newFakeVault(t)
+	f.Write("sys/policies/password/app", map[string]interface{}{"policy": `length = 20`})
+	f.Write("sys/audit/hash", map[string]interface{}{"type": "file"})
+
+	var first, second string
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				// Generated values are hashed like the others.
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "1", `password_policy = "app"`, `audit_hash_path = "hash"`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &first),
+					func(s *terraform.State) error {
+						if !strings.HasPrefix(first, "app-") {
+							return fmt.Errorf("value %q wasn't generated with the policy", first)
+						}
+						return testResourceGenericSecret_noPlaintext(first, "zap")(s)
+					},
+				),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "2", `password_policy = "app"`, `audit_hash_path = "hash"`),
+				Check: resource.ComposeTestCheckFunc(
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/foo", "zap"),
+					testResourceGenericSecret_fakeVaultValue(f, "secret/data/foo", "password", &second),
+					func(s *terraform.State) error {
+						if second == first {
+							return fmt.Errorf("generated value %q wasn't rotated", second)
+						}
+						return testResourceGenericSecret_noPlaintext(second, "zap")(s)
+					},
+				),
+			},
+			{
+				Config:      f.ProviderConfig() + testResourceGenericSecret_generatorConfig("zap", "3", `password_policy = "missing"`, `audit_hash_path = "hash"`),
+				ExpectError: regexp.MustCompile(`error generating a password with "sys/policies/password/missing/generate"`),
+			},
+		},
+	})
+}
+
+func TestResourceGenericSecret_fakeVaultGeneratorConflict(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_generic_secret" "test" {
+    path      = "secret/foo"
+    data_json = "{\"password\": \"hunter2\"}"
+
+    generator {
+        key = "password"
+    }
+}`,
+				ExpectError: regexp.MustCompile(`"password" is both in data_json and generated`),
+			},
+		},
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"log"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
 	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
@@ -99,7 +98,10 @@ func identityGroupUpdateFields(d *schema.ResourceData, data map[string]interface
 }
 
 func identityGroupCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	name := d.Get("name").(string)
 	typeValue := d.Get("type").(string)
@@ -128,7 +130,10 @@ func identityGroupCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func identityGroupUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	id := d.Id()
 
 	log.Printf("[DEBUG] Updating IdentityGroup %q", id)
@@ -138,7 +143,7 @@ func identityGroupUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	identityGroupUpdateFields(d, data)
 
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 
 	if err != nil {
 		return fmt.Errorf("error updating IdentityGroup %q: %s", id, err)
@@ -149,19 +154,18 @@ func identityGroupUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func identityGroupRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	id := d.Id()
 
 	path := identityGroupIDPath(id)
 
 	log.Printf("[DEBUG] Reading IdentityGroup %s from %q", id, path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
-		// We need to check if the secret_id has expired
-		if util.IsExpiredTokenErr(err) {
-			return nil
-		}
-		return fmt.Errorf("error reading AppRole auth backend role SecretID %q: %s", id, err)
+	if err != nil && !util.IsNotFound(err) {
+		return fmt.Errorf("error reading IdentityGroup %q: %s", id, err)
 	}
 	log.Printf("[DEBUG] Read IdentityGroup %s", id)
 	if resp == nil {
@@ -177,15 +181,18 @@ func identityGroupRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func identityGroupDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	id := d.Id()
 
 	path := identityGroupIDPath(id)
 
 	log.Printf("[DEBUG] Deleting IdentityGroup %q", id)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
-		return fmt.Errorf("error IdentityGroup %q", id)
+		return fmt.Errorf("error deleting IdentityGroup %q: %s", id, err)
 	}
 	log.Printf("[DEBUG] Deleted IdentityGroup %q", id)
 
@@ -193,7 +200,10 @@ func identityGroupDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func identityGroupExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 	id := d.Id()
 
 	path := identityGroupIDPath(id)
@@ -207,7 +217,7 @@ func identityGroupExists(d *schema.ResourceData, meta interface{}) (bool, error)
 
 	log.Printf("[DEBUG] Checking if IdentityGroup %q exists", key)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if IdentityGroup %q exists: %s", key, err)
 	}
 	log.Printf("[DEBUG] Checked if IdentityGroup %q exists", key)
@@ -5,7 +5,7 @@ import (
 	"log"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 const identityGroupAliasPath = "/identity/group-alias"
@@ -48,7 +48,10 @@ func identityGroupAliasResource() *schema.Resource {
 }
 
 func identityGroupAliasCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	name := d.Get("name").(string)
 	mountAccessor := d.Get("mount_accessor").(string)
@@ -77,7 +80,10 @@ func identityGroupAliasCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func identityGroupAliasUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	id := d.Id()
 
 	log.Printf("[DEBUG] Updating IdentityGroupAlias %q", id)
@@ -112,14 +118,17 @@ func identityGroupAliasUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func identityGroupAliasRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	id := d.Id()
 
 	path := identityGroupAliasIDPath(id)
 
 	log.Printf("[DEBUG] Reading IdentityGroupAlias %s from %q", id, path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading IdentityGroupAlias %q: %s", id, err)
 	}
 	log.Printf("[DEBUG] Read IdentityGroupAlias %s", id)
@@ -136,13 +145,16 @@ func identityGroupAliasRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func identityGroupAliasDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	id := d.Id()
 
 	path := identityGroupAliasIDPath(id)
 
 	log.Printf("[DEBUG] Deleting IdentityGroupAlias %q", id)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("error IdentityGroupAlias %q", id)
 	}
@@ -152,7 +164,10 @@ func identityGroupAliasDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func identityGroupAliasExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 	id := d.Id()
 
 	path := identityGroupAliasIDPath(id)
@@ -166,7 +181,7 @@ func identityGroupAliasExists(d *schema.ResourceData, meta interface{}) (bool, e
 
 	log.Printf("[DEBUG] Checking if IdentityGroupAlias %q exists", key)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if IdentityGroupAlias %q exists: %s", key, err)
 	}
 	log.Printf("[DEBUG] Checked if IdentityGroupAlias %q exists", key)
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
 	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
@@ -112,7 +111,10 @@ func jwtAuthBackendRoleResource() *schema.Resource {
 }
 
 func jwtAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	role := d.Get("role_name").(string)
@@ -120,7 +122,7 @@ func jwtAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Writing JWT auth backend role %q", path)
 	data := jwtAuthBackendRoleDataToWrite(d)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("error writing JWT auth backend role %q: %s", path, err)
 	}
@@ -142,7 +144,10 @@ func jwtAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func jwtAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	backend, err := jwtAuthBackendRoleBackendFromPath(path)
@@ -157,7 +162,7 @@ func jwtAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Reading JWT auth backend role %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading JWT auth backend role %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read JWT auth backend role %q", path)
@@ -226,12 +231,15 @@ func jwtAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func jwtAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Updating JWT auth backend role %q", path)
 	data := jwtAuthBackendRoleDataToWrite(d)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 
 	d.SetId(path)
 
@@ -256,12 +264,15 @@ func jwtAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func jwtAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting JWT auth backend role %q", path)
-	_, err := client.Logical().Delete(path)
-	if err != nil && !util.Is404(err) {
+	_, err = client.Logical().Delete(path)
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error deleting JWT auth backend role %q", path)
 	} else if err != nil {
 		log.Printf("[DEBUG] JWT auth backend role %q not found, removing from state", path)
@@ -274,13 +285,16 @@ func jwtAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func jwtAuthBackendRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if JWT auth backend role %q exists", path)
 
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if JWT auth backend role %q exists: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if JWT auth backend role %q exists", path)
@@ -7,7 +7,6 @@ import (
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/hashicorp/vault/api"
 )
 
 func TestAccJWTAuthBackendRole_import(t *testing.T) {
@@ -337,7 +336,7 @@ func TestAccJWTAuthBackendRole_fullUpdate(t *testing.T) {
 }
 
 func testAccCheckJWTAuthBackendRoleDestroy(s *terraform.State) error {
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "vault_jwt_auth_backend_role" {
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
 	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
@@ -110,7 +109,10 @@ func kubernetesAuthBackendRoleBackendFromPath(path string) (string, error) {
 }
 
 func kubernetesAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	role := d.Get("role_name").(string)
@@ -164,7 +166,7 @@ func kubernetesAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) e
 		data["period"] = v.(int)
 	}
 
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("error writing Kubernetes auth backend role %q: %s", path, err)
 	}
@@ -175,7 +177,10 @@ func kubernetesAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) e
 }
 
 func kubernetesAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	backend, err := kubernetesAuthBackendRoleBackendFromPath(path)
@@ -190,7 +195,7 @@ func kubernetesAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) err
 
 	log.Printf("[DEBUG] Reading Kubernetes auth backend role: %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading Kubernetes auth backend role %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read Kubernetes auth backend role: %q", path)
@@ -252,7 +257,10 @@ func kubernetesAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) err
 }
 
 func kubernetesAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Updating Kubernetes auth backend role %q", path)
@@ -293,7 +301,7 @@ func kubernetesAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) e
 		data["period"] = v.(int)
 	}
 
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("error updating Kubernetes auth backend role %q: %s", path, err)
 	}
@@ -307,12 +315,15 @@ func kubernetesAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) e
 }
 
 func kubernetesAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting Kubernetes auth backend role %q", path)
-	_, err := client.Logical().Delete(path)
-	if err != nil && !util.Is404(err) {
+	_, err = client.Logical().Delete(path)
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error deleting Kubernetes auth backend role %q", path)
 	} else if err != nil {
 		log.Printf("[DEBUG] Kubernetes auth backend role %q not found, removing from state", path)
@@ -325,13 +336,16 @@ func kubernetesAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) e
 }
 
 func kubernetesAuthBackendRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if Kubernetes auth backend role %q exists", path)
 
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if Kubernetes auth backend role %q exists: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if Kubernetes auth backend role %q exists", path)
@@ -0,0 +1,166 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+)
+
+func kvSecretBackendV2Resource() *schema.Resource {
+	return &schema.Resource{
+		Create: kvSecretBackendV2Create,
+		Update: kvSecretBackendV2Update,
+		Delete: kvSecretBackendV2Delete,
+		Read:   kvSecretBackendV2Read,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"mount": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the KV version 2 secrets engine is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"max_versions": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of versions to keep for each secret. Vault keeps 10 when set to 0.",
+			},
+
+			"cas_required": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Require the cas parameter on every write to a secret.",
+			},
+
+			"delete_version_after": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of seconds after which versions are deleted. Versions are kept until deleted when set to 0.",
+			},
+		},
+	}
+}
+
+func kvSecretBackendV2ConfigPath(mount string) string {
+	return strings.Trim(mount, "/") + "/config"
+}
+
+func kvSecretBackendV2Create(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	mount := strings.Trim(d.Get("mount").(string), "/")
+	if err := kvV2MountCheck(client, mount, meta.(*ProviderMeta).ServerVersion()); err != nil {
+		return err
+	}
+
+	d.SetId(mount)
+
+	return kvSecretBackendV2Update(d, meta)
+}
+
+func kvSecretBackendV2Update(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := kvSecretBackendV2ConfigPath(d.Id())
+	data := map[string]interface{}{
+		"max_versions":         d.Get("max_versions").(int),
+		"cas_required":         d.Get("cas_required").(bool),
+		"delete_version_after": fmt.Sprintf("%ds", d.Get("delete_version_after").(int)),
+	}
+
+	log.Printf("[DEBUG] Writing KV version 2 backend config to %q", path)
+	if _, err := client.Logical().Write(path, data); err != nil {
+		return fmt.Errorf("error writing KV version 2 backend config to %q: %s", path, err)
+	}
+	log.Printf("[DEBUG] Wrote KV version 2 backend config to %q", path)
+
+	return kvSecretBackendV2Read(d, meta)
+}
+
+func kvSecretBackendV2Read(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := kvSecretBackendV2ConfigPath(d.Id())
+
+	log.Printf("[DEBUG] Reading KV version 2 backend config from %q", path)
+	secret, err := client.Logical().Read(path)
+	if err != nil && !util.IsNotFound(err) {
+		return fmt.Errorf("error reading KV version 2 backend config from %q: %s", path, err)
+	}
+	if secret == nil {
+		log.Printf("[WARN] KV version 2 backend config %q not found, removing from state", path)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("mount", d.Id())
+
+	if v, ok := secret.Data["max_versions"].(json.Number); ok {
+		maxVersions, err := v.Int64()
+		if err != nil {
+			return fmt.Errorf("unexpected max_versions %q in %q: %s", v, path, err)
+		}
+		d.Set("max_versions", int(maxVersions))
+	}
+	if v, ok := secret.Data["cas_required"].(bool); ok {
+		d.Set("cas_required", v)
+	}
+	deleteVersionAfter, err := kvDurationSeconds(secret.Data["delete_version_after"])
+	if err != nil {
+		return fmt.Errorf("unexpected delete_version_after in %q: %s", path, err)
+	}
+	d.Set("delete_version_after", deleteVersionAfter)
+
+	return nil
+}
+
+func kvSecretBackendV2Delete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// The config can't be removed, only set back to the engine's defaults.
+	path := kvSecretBackendV2ConfigPath(d.Id())
+	data := map[string]interface{}{
+		"max_versions":         0,
+		"cas_required":         false,
+		"delete_version_after": "0s",
+	}
+
+	log.Printf("[DEBUG] Resetting KV version 2 backend config %q", path)
+	_, err = client.Logical().Write(path, data)
+	if err != nil && !util.IsNotFound(err) {
+		return fmt.Errorf("error resetting KV version 2 backend config %q: %s", path, err)
+	}
+	log.Printf("[DEBUG] Reset KV version 2 backend config %q", path)
+
+	return nil
+}
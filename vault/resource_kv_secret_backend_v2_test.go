@@ -0,0 +1,139 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceKVSecretBackendV2(t *testing.T) {
+	mount := acctest.RandomWithPrefix("tf-test-kv")
+	resource.Test(t, resource.TestCase{
+		Providers: testProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testResourceKVSecretV2_mountConfig(mount) + testResourceKVSecretBackendV2_config("${vault_mount.kv.path}", 5, true, 3600),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_backend_v2.test", "mount", mount),
+					resource.TestCheckResourceAttr("vault_kv_secret_backend_v2.test", "max_versions", "5"),
+					resource.TestCheckResourceAttr("vault_kv_secret_backend_v2.test", "cas_required", "true"),
+					resource.TestCheckResourceAttr("vault_kv_secret_backend_v2.test", "delete_version_after", "3600"),
+				),
+			},
+			{
+				ResourceName:      "vault_kv_secret_backend_v2.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testResourceKVSecretBackendV2_config(mount string, maxVersions int, casRequired bool, deleteVersionAfter int) string {
+	return fmt.Sprintf(`
+resource "vault_kv_secret_backend_v2" "test" {
+  mount                = %q
+  max_versions         = %d
+  cas_required         = %t
+  delete_version_after = %d
+}
+`, mount, maxVersions, casRequired, deleteVersionAfter)
+}
+
+func testResourceKVSecretBackendV2_fakeVaultCheck(f *fakeVault, maxVersions int, casRequired bool, deleteVersionAfter string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		config := f.Read("secret/config")
+		if config == nil {
+			return fmt.Errorf("secret/config not found")
+		}
+		if got := fmt.Sprint(config["max_versions"]); got != fmt.Sprint(maxVersions) {
+			return fmt.Errorf("bad max_versions: want %d, got %s", maxVersions, got)
+		}
+		if got := config["cas_required"]; got != casRequired {
+			return fmt.Errorf("bad cas_required: want %t, got %v", casRequired, got)
+		}
+		if got := config["delete_version_after"]; got != deleteVersionAfter {
+			return fmt.Errorf("bad delete_version_after: want %q, got %v", deleteVersionAfter, got)
+		}
+		return nil
+	}
+}
+
+func TestResourceKVSecretBackendV2_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers:    f.Providers(),
+		CheckDestroy: testResourceKVSecretBackendV2_fakeVaultCheck(f, 0, false, "0s"),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceKVSecretBackendV2_config("secret", 5, true, 3600),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_backend_v2.test", "mount", "secret"),
+					resource.TestCheckResourceAttr("vault_kv_secret_backend_v2.test", "max_versions", "5"),
+					resource.TestCheckResourceAttr("vault_kv_secret_backend_v2.test", "cas_required", "true"),
+					resource.TestCheckResourceAttr("vault_kv_secret_backend_v2.test", "delete_version_after", "3600"),
+					testResourceKVSecretBackendV2_fakeVaultCheck(f, 5, true, "1h0m0s"),
+				),
+			},
+			{
+				// Changes made outside of Terraform are reverted.
+				PreConfig: func() {
+					f.Write("secret/config", map[string]interface{}{"max_versions": 20, "cas_required": false})
+				},
+				Config: f.ProviderConfig() + testResourceKVSecretBackendV2_config("secret", 5, true, 3600),
+				Check:  testResourceKVSecretBackendV2_fakeVaultCheck(f, 5, true, "1h0m0s"),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceKVSecretBackendV2_config("secret", 0, false, 0),
+				Check:  testResourceKVSecretBackendV2_fakeVaultCheck(f, 0, false, "0s"),
+			},
+			{
+				ResourceName:      "vault_kv_secret_backend_v2.test",
+				ImportState:       true,
+				ImportStateId:     "secret",
+				ImportStateVerify: true,
+				Config:            f.ProviderConfig() + testResourceKVSecretBackendV2_config("secret", 0, false, 0),
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretBackendV2_fakeVaultDrift(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceKVSecretBackendV2_config("secret", 5, true, 0),
+			},
+			{
+				PreConfig: func() {
+					f.Write("secret/config", map[string]interface{}{"max_versions": 20})
+				},
+				Config:             f.ProviderConfig() + testResourceKVSecretBackendV2_config("secret", 5, true, 0),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretBackendV2_fakeVaultNotKVv2(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("kv", "kv", nil)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config:      f.ProviderConfig() + testResourceKVSecretBackendV2_config("kv", 5, false, 0),
+				ExpectError: regexp.MustCompile(`"kv" is not the path of a KV version 2 mount`),
+			},
+		},
+	})
+}
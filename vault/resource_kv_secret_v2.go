@@ -0,0 +1,252 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/vault/api"
+)
+
+func kvSecretV2Resource() *schema.Resource {
+	return &schema.Resource{
+		Create: kvSecretV2Write,
+		Update: kvSecretV2Write,
+		Delete: kvSecretV2Delete,
+		Read:   kvSecretV2Read,
+		Importer: &schema.ResourceImporter{
+			State: kvSecretV2Import,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"mount": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the KV version 2 secrets engine is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path of the secret, relative to the mount.",
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"path": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Full path of the secret's data, including the mount and data/ prefix.",
+			},
+
+			"data_json": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "JSON-encoded secret data to write.",
+				StateFunc:    NormalizeDataJSON,
+				ValidateFunc: ValidateDataJSON,
+				Sensitive:    true,
+			},
+
+			"data": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Map of strings read from Vault.",
+				Sensitive:   true,
+			},
+
+			"cas": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Version that the secret must be at for a write to succeed. Set to 0 to only write the secret if it doesn't exist.",
+			},
+
+			"delete_all_versions": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Permanently delete every version of the secret, and its metadata, on destroy. Otherwise only the latest version is soft deleted.",
+			},
+
+			"version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Version of the secret.",
+			},
+
+			"created_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time at which the version of the secret was created.",
+			},
+
+			"metadata": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Metadata of the version of the secret.",
+			},
+		},
+	}
+}
+
+// kvSecretV2Path returns the path of a secret under one of the API prefixes
+// of a KV version 2 mount, such as data or metadata.
+func kvSecretV2Path(mount, apiPrefix, name string) string {
+	return strings.Trim(mount, "/") + "/" + apiPrefix + "/" + strings.Trim(name, "/")
+}
+
+// kvV2MountCheck returns an error unless mount is a KV version 2 mount.
+func kvV2MountCheck(client *api.Client, mount string, serverVersion *version.Version) error {
+	mountPath, v2, err := isKVv2(mount, client, serverVersion)
+	if err != nil {
+		return fmt.Errorf("error determining if %q is a KV version 2 mount: %s", mount, err)
+	}
+	if !v2 || strings.Trim(mountPath, "/") != strings.Trim(mount, "/") {
+		return fmt.Errorf("%q is not the path of a KV version 2 mount", mount)
+	}
+	return nil
+}
+
+func kvSecretV2Write(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	mount := d.Get("mount").(string)
+	if d.IsNewResource() {
+		if err := kvV2MountCheck(client, mount, meta.(*ProviderMeta).ServerVersion()); err != nil {
+			return err
+		}
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(d.Get("data_json").(string)), &data); err != nil {
+		return fmt.Errorf("data_json %#v syntax error: %s", d.Get("data_json"), err)
+	}
+
+	options := map[string]interface{}{}
+	if cas, ok := d.GetOkExists("cas"); ok {
+		options["cas"] = cas.(int)
+	}
+
+	path := kvSecretV2Path(mount, "data", d.Get("name").(string))
+
+	log.Printf("[DEBUG] Writing KV version 2 secret to %q", path)
+	_, err = client.Logical().Write(path, map[string]interface{}{
+		"data":    data,
+		"options": options,
+	})
+	if err != nil {
+		return fmt.Errorf("error writing %q to Vault: %s", path, err)
+	}
+	log.Printf("[DEBUG] Wrote KV version 2 secret to %q", path)
+
+	d.SetId(path)
+
+	return kvSecretV2Read(d, meta)
+}
+
+func kvSecretV2Read(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+
+	log.Printf("[DEBUG] Reading KV version 2 secret from %q", path)
+	secret, err := kvReadRequest(client, path, nil)
+	if err != nil {
+		return fmt.Errorf("error reading %q from Vault: %s", path, err)
+	}
+
+	// The latest version of a deleted or destroyed secret is still
+	// described by its metadata, but has no data.
+	var data map[string]interface{}
+	if secret != nil {
+		data, _ = secret.Data["data"].(map[string]interface{})
+	}
+	if data == nil {
+		log.Printf("[WARN] KV version 2 secret %q not found, removing from state", path)
+		d.SetId("")
+		return nil
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON for %q: %s", path, err)
+	}
+
+	d.Set("path", path)
+	d.Set("data_json", string(jsonData))
+	d.Set("data", serializeDataMapToString(data))
+
+	metadata, _ := secret.Data["metadata"].(map[string]interface{})
+	d.Set("metadata", serializeDataMapToString(metadata))
+	if v, ok := metadata["version"].(json.Number); ok {
+		version, err := v.Int64()
+		if err != nil {
+			return fmt.Errorf("unexpected version %q for %q: %s", v, path, err)
+		}
+		d.Set("version", int(version))
+	}
+	if v, ok := metadata["created_time"].(string); ok {
+		d.Set("created_time", v)
+	}
+
+	return nil
+}
+
+func kvSecretV2Delete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+	if d.Get("delete_all_versions").(bool) {
+		path = kvSecretV2Path(d.Get("mount").(string), "metadata", d.Get("name").(string))
+	}
+
+	log.Printf("[DEBUG] Deleting KV version 2 secret %q", path)
+	if _, err := client.Logical().Delete(path); err != nil {
+		return fmt.Errorf("error deleting %q from Vault: %s", path, err)
+	}
+	log.Printf("[DEBUG] Deleted KV version 2 secret %q", path)
+
+	return nil
+}
+
+func kvSecretV2Import(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	path := strings.Trim(d.Id(), "/")
+	mountPath, v2, err := isKVv2(path, client, meta.(*ProviderMeta).ServerVersion())
+	if err != nil {
+		return nil, fmt.Errorf("error determining the mount of %q: %s", path, err)
+	}
+	mount := strings.Trim(mountPath, "/")
+	if !v2 || !strings.HasPrefix(path, mount+"/data/") {
+		return nil, fmt.Errorf("import ID %q must be the path of a KV version 2 secret's data, as in <mount>/data/<name>", d.Id())
+	}
+
+	d.SetId(path)
+	d.Set("mount", mount)
+	d.Set("name", strings.TrimPrefix(path, mount+"/data/"))
+
+	return []*schema.ResourceData{d}, nil
+}
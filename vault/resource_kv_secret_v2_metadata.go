@@ -0,0 +1,253 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+)
+
+func kvSecretV2MetadataResource() *schema.Resource {
+	return &schema.Resource{
+		Create: kvSecretV2MetadataWrite,
+		Update: kvSecretV2MetadataWrite,
+		Delete: kvSecretV2MetadataDelete,
+		Read:   kvSecretV2MetadataRead,
+		Importer: &schema.ResourceImporter{
+			State: kvSecretV2MetadataImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"mount": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the KV version 2 secrets engine is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path of the secret, relative to the mount.",
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"path": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Full path of the secret's metadata, including the mount and metadata/ prefix.",
+			},
+
+			"max_versions": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of versions of the secret to keep. The engine's setting applies when set to 0.",
+			},
+
+			"cas_required": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Require the cas parameter on every write to the secret.",
+			},
+
+			"delete_version_after": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of seconds after which versions of the secret are deleted. The engine's setting applies when set to 0.",
+			},
+
+			"custom_metadata": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Arbitrary string keys and values describing the secret.",
+			},
+
+			"current_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Latest version of the secret.",
+			},
+
+			"oldest_version": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Oldest version of the secret that is kept.",
+			},
+
+			"created_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time at which the secret was created.",
+			},
+
+			"updated_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time at which the secret or its metadata was last changed.",
+			},
+		},
+	}
+}
+
+func kvSecretV2MetadataWrite(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	mount := d.Get("mount").(string)
+	if d.IsNewResource() {
+		if err := kvV2MountCheck(client, mount, meta.(*ProviderMeta).ServerVersion()); err != nil {
+			return err
+		}
+	}
+
+	metadataPath := kvSecretV2Path(mount, "metadata", d.Get("name").(string))
+
+	data := map[string]interface{}{
+		"max_versions":         d.Get("max_versions").(int),
+		"cas_required":         d.Get("cas_required").(bool),
+		"delete_version_after": fmt.Sprintf("%ds", d.Get("delete_version_after").(int)),
+	}
+	// Only sent when it's used, since older servers don't support it.
+	if v := d.Get("custom_metadata").(map[string]interface{}); len(v) > 0 || d.HasChange("custom_metadata") {
+		data["custom_metadata"] = v
+	}
+
+	log.Printf("[DEBUG] Writing KV version 2 metadata to %q", metadataPath)
+	if _, err := client.Logical().Write(metadataPath, data); err != nil {
+		return fmt.Errorf("error writing KV version 2 metadata to %q: %s", metadataPath, err)
+	}
+	log.Printf("[DEBUG] Wrote KV version 2 metadata to %q", metadataPath)
+
+	d.SetId(metadataPath)
+
+	return kvSecretV2MetadataRead(d, meta)
+}
+
+func kvSecretV2MetadataRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	metadataPath := d.Id()
+
+	log.Printf("[DEBUG] Reading KV version 2 metadata from %q", metadataPath)
+	secret, err := client.Logical().Read(metadataPath)
+	if err != nil && !util.IsNotFound(err) {
+		return fmt.Errorf("error reading KV version 2 metadata from %q: %s", metadataPath, err)
+	}
+	if secret == nil {
+		log.Printf("[WARN] KV version 2 metadata %q not found, removing from state", metadataPath)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("path", metadataPath)
+
+	for _, k := range []string{"max_versions", "current_version", "oldest_version"} {
+		v, ok := secret.Data[k].(json.Number)
+		if !ok {
+			continue
+		}
+		n, err := v.Int64()
+		if err != nil {
+			return fmt.Errorf("unexpected %s %q in %q: %s", k, v, metadataPath, err)
+		}
+		d.Set(k, int(n))
+	}
+	if v, ok := secret.Data["cas_required"].(bool); ok {
+		d.Set("cas_required", v)
+	}
+	deleteVersionAfter, err := kvDurationSeconds(secret.Data["delete_version_after"])
+	if err != nil {
+		return fmt.Errorf("unexpected delete_version_after in %q: %s", metadataPath, err)
+	}
+	d.Set("delete_version_after", deleteVersionAfter)
+
+	customMetadata, _ := secret.Data["custom_metadata"].(map[string]interface{})
+	if err := d.Set("custom_metadata", serializeDataMapToString(customMetadata)); err != nil {
+		return fmt.Errorf("error setting custom_metadata for %q: %s", metadataPath, err)
+	}
+	d.Set("created_time", secret.Data["created_time"])
+	d.Set("updated_time", secret.Data["updated_time"])
+
+	return nil
+}
+
+// kvSecretV2MetadataDelete sets the secret's metadata back to its defaults.
+// Deleting the metadata itself would also permanently delete every version
+// of the secret, which the resource doesn't own.
+func kvSecretV2MetadataDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	metadataPath := d.Id()
+
+	secret, err := client.Logical().Read(metadataPath)
+	if err != nil {
+		return fmt.Errorf("error reading KV version 2 metadata from %q: %s", metadataPath, err)
+	}
+	if secret == nil {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"max_versions":         0,
+		"cas_required":         false,
+		"delete_version_after": "0s",
+	}
+	if v, ok := secret.Data["custom_metadata"].(map[string]interface{}); ok && len(v) > 0 {
+		data["custom_metadata"] = map[string]interface{}{}
+	}
+
+	log.Printf("[DEBUG] Resetting KV version 2 metadata %q", metadataPath)
+	if _, err := client.Logical().Write(metadataPath, data); err != nil {
+		return fmt.Errorf("error resetting KV version 2 metadata %q: %s", metadataPath, err)
+	}
+	log.Printf("[DEBUG] Reset KV version 2 metadata %q", metadataPath)
+
+	return nil
+}
+
+func kvSecretV2MetadataImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	path := strings.Trim(d.Id(), "/")
+	mountPath, v2, err := isKVv2(path, client, meta.(*ProviderMeta).ServerVersion())
+	if err != nil {
+		return nil, fmt.Errorf("error determining the mount of %q: %s", path, err)
+	}
+	mount := strings.Trim(mountPath, "/")
+	if !v2 || !strings.HasPrefix(path, mount+"/metadata/") {
+		return nil, fmt.Errorf("import ID %q must be the path of a KV version 2 secret's metadata, as in <mount>/metadata/<name>", d.Id())
+	}
+
+	d.SetId(path)
+	d.Set("mount", mount)
+	d.Set("name", strings.TrimPrefix(path, mount+"/metadata/"))
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,247 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceKVSecretV2Metadata(t *testing.T) {
+	mount := acctest.RandomWithPrefix("tf-test-kv")
+	resource.Test(t, resource.TestCase{
+		Providers: testProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testResourceKVSecretV2_mountConfig(mount) + testResourceKVSecretV2Metadata_config("${vault_mount.kv.path}", "app", 5, true, 3600, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "mount", mount),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "name", "app"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "path", mount+"/metadata/app"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "max_versions", "5"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "cas_required", "true"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "delete_version_after", "3600"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "current_version", "0"),
+				),
+			},
+			{
+				ResourceName:      "vault_kv_secret_v2_metadata.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testResourceKVSecretV2Metadata_config(mount, name string, maxVersions int, casRequired bool, deleteVersionAfter int, customMetadata string) string {
+	return fmt.Sprintf(`
+resource "vault_kv_secret_v2_metadata" "test" {
+  mount                = %q
+  name                 = %q
+  max_versions         = %d
+  cas_required         = %t
+  delete_version_after = %d
+%s
+}
+`, mount, name, maxVersions, casRequired, deleteVersionAfter, customMetadata)
+}
+
+func testResourceKVSecretV2Metadata_fakeVaultCheck(f *fakeVault, path string, maxVersions int, casRequired bool, deleteVersionAfter string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		meta := f.Read(path)
+		if meta == nil {
+			return fmt.Errorf("%s not found", path)
+		}
+		if got := fmt.Sprint(meta["max_versions"]); got != fmt.Sprint(maxVersions) {
+			return fmt.Errorf("bad max_versions: want %d, got %s", maxVersions, got)
+		}
+		if got := meta["cas_required"]; got != casRequired {
+			return fmt.Errorf("bad cas_required: want %t, got %v", casRequired, got)
+		}
+		if got := meta["delete_version_after"]; got != deleteVersionAfter {
+			return fmt.Errorf("bad delete_version_after: want %q, got %v", deleteVersionAfter, got)
+		}
+		return nil
+	}
+}
+
+func TestResourceKVSecretV2Metadata_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	f.Write("secret/data/app", map[string]interface{}{
+		"data": map[string]interface{}{"zip": "zap"},
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testResourceKVSecretV2Metadata_fakeVaultCheck(f, "secret/metadata/app", 0, false, "0s"),
+			// The data belongs to whoever writes it, and is kept.
+			testResourceGenericSecret_fakeVaultCheck(f, "secret/data/app", "zap"),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 5, true, 3600, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "mount", "secret"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "name", "app"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "path", "secret/metadata/app"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "max_versions", "5"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "cas_required", "true"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "delete_version_after", "3600"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "current_version", "1"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "oldest_version", "1"),
+					resource.TestCheckResourceAttrSet("vault_kv_secret_v2_metadata.test", "created_time"),
+					resource.TestCheckResourceAttrSet("vault_kv_secret_v2_metadata.test", "updated_time"),
+					testResourceKVSecretV2Metadata_fakeVaultCheck(f, "secret/metadata/app", 5, true, "1h0m0s"),
+				),
+			},
+			{
+				// Changes made outside of Terraform are reverted.
+				PreConfig: func() {
+					f.Write("secret/metadata/app", map[string]interface{}{"max_versions": 20, "cas_required": false})
+				},
+				Config: f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 5, true, 3600, ""),
+				Check:  testResourceKVSecretV2Metadata_fakeVaultCheck(f, "secret/metadata/app", 5, true, "1h0m0s"),
+			},
+			{
+				ResourceName:      "vault_kv_secret_v2_metadata.test",
+				ImportState:       true,
+				ImportStateId:     "secret/metadata/app",
+				ImportStateVerify: true,
+				Config:            f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 5, true, 3600, ""),
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretV2Metadata_fakeVaultNoData(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				// The metadata can be managed before the secret is written.
+				Config: f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 3, false, 0, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "current_version", "0"),
+					testResourceKVSecretV2Metadata_fakeVaultCheck(f, "secret/metadata/app", 3, false, "0s"),
+				),
+			},
+			{
+				// Deleted outside of Terraform.
+				PreConfig: func() {
+					f.Delete("secret/metadata/app")
+				},
+				Config:             f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 3, false, 0, ""),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretV2Metadata_fakeVaultCustomMetadata(t *testing.T) {
+	f := newFakeVault(t)
+	f.SetVersion("1.9.0")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(s *terraform.State) error {
+			meta := f.Read("secret/metadata/app")
+			if custom, _ := meta["custom_metadata"].(map[string]interface{}); len(custom) != 0 {
+				return fmt.Errorf("custom_metadata not removed: %v", custom)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 0, false, 0, `
+  custom_metadata = {
+    owner = "platform"
+    tier  = "1"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "custom_metadata.%", "2"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "custom_metadata.owner", "platform"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "custom_metadata.tier", "1"),
+				),
+			},
+			{
+				PreConfig: func() {
+					f.Write("secret/metadata/app", map[string]interface{}{
+						"custom_metadata": map[string]interface{}{"owner": "someone-else"},
+					})
+				},
+				Config: f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 0, false, 0, `
+  custom_metadata = {
+    owner = "platform"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "custom_metadata.%", "1"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "custom_metadata.owner", "platform"),
+				),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 0, false, 0, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2_metadata.test", "custom_metadata.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretV2Metadata_fakeVaultCustomMetadataOldVault(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 0, false, 0, `
+  custom_metadata = {
+    owner = "platform"
+  }`),
+				ExpectError: regexp.MustCompile(`"custom_metadata" requires Vault 1.9.0 or later, but the server is running 0.11.1`),
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretV2Metadata_fakeVaultNotKVv2(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("kv", "kv", nil)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config:      f.ProviderConfig() + testResourceKVSecretV2Metadata_config("kv", "app", 5, false, 0, ""),
+				ExpectError: regexp.MustCompile(`"kv" is not the path of a KV version 2 mount`),
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretV2Metadata_fakeVaultImportInvalid(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("kv", "kv", nil)
+
+	for _, id := range []string{"secret/app", "secret/data/app", "kv/metadata/app"} {
+		resource.UnitTest(t, resource.TestCase{
+			Providers: f.Providers(),
+			Steps: []resource.TestStep{
+				{
+					ResourceName:  "vault_kv_secret_v2_metadata.test",
+					ImportState:   true,
+					ImportStateId: id,
+					Config:        f.ProviderConfig() + testResourceKVSecretV2Metadata_config("secret", "app", 0, false, 0, ""),
+					ExpectError:   regexp.MustCompile(`must be the path of a KV version 2 secret's metadata, as in <mount>/metadata/<name>`),
+				},
+			},
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceKVSecretV2(t *testing.T) {
+	mount := acctest.RandomWithPrefix("tf-test-kv")
+	resource.Test(t, resource.TestCase{
+		Providers:    testProviders,
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testResourceKVSecretV2_checkDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testResourceKVSecretV2_mountConfig(mount) + testResourceKVSecretV2_config("vault_mount.kv.path", "zap"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "path", mount+"/data/app/config"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "data.zip", "zap"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "version", "1"),
+					resource.TestCheckResourceAttrSet("vault_kv_secret_v2.test", "created_time"),
+				),
+			},
+			{
+				Config: testResourceKVSecretV2_mountConfig(mount) + testResourceKVSecretV2_config("vault_mount.kv.path", "zoop"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "data.zip", "zoop"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "version", "2"),
+				),
+			},
+			{
+				ResourceName:            "vault_kv_secret_v2.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"delete_all_versions"},
+			},
+		},
+	})
+}
+
+func testResourceKVSecretV2_mountConfig(mount string) string {
+	return fmt.Sprintf(`
+resource "vault_mount" "kv" {
+  path = %q
+  type = "kv"
+  options = {
+    version = "2"
+  }
+}
+`, mount)
+}
+
+func testResourceKVSecretV2_config(mount, value string) string {
+	return fmt.Sprintf(`
+resource "vault_kv_secret_v2" "test" {
+  mount     = "${%s}"
+  name      = "app/config"
+  data_json = <<EOT
+{
+  "zip": %q
+}
+EOT
+}
+`, mount, value)
+}
+
+func testResourceKVSecretV2_checkDestroy(s *terraform.State) error {
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vault_kv_secret_v2" {
+			continue
+		}
+		secret, err := kvReadRequest(client, rs.Primary.ID, nil)
+		if err != nil {
+			return err
+		}
+		if secret != nil && secret.Data["data"] != nil {
+			return fmt.Errorf("secret %q still exists", rs.Primary.ID)
+		}
+	}
+	return nil
+}
+
+func TestResourceKVSecretV2_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	config := f.ProviderConfig() + `
+resource "vault_kv_secret_v2" "test" {
+  mount     = "secret"
+  name      = "app/config"
+  data_json = "{\"zip\": \"zap\"}"
+}
+`
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(s *terraform.State) error {
+			// Only the latest version is soft deleted.
+			if f.Read("secret/data/app/config") != nil {
+				return fmt.Errorf("secret still exists")
+			}
+			if f.Read("secret/metadata/app/config") == nil {
+				return fmt.Errorf("expected the secret's metadata to be kept")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "path", "secret/data/app/config"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "data.zip", "zap"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "version", "1"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "metadata.version", "1"),
+					resource.TestCheckResourceAttrSet("vault_kv_secret_v2.test", "created_time"),
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/app/config", "zap"),
+				),
+			},
+			{
+				// A new version written outside of Terraform is replaced.
+				PreConfig: func() {
+					f.Write("secret/data/app/config", map[string]interface{}{
+						"data": map[string]interface{}{"zip": "changed"},
+					})
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "data.zip", "zap"),
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "version", "3"),
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/app/config", "zap"),
+				),
+			},
+			{
+				// A soft deleted secret is written again.
+				PreConfig: func() {
+					f.Delete("secret/data/app/config")
+				},
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "version", "4"),
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/app/config", "zap"),
+				),
+			},
+			{
+				ResourceName:            "vault_kv_secret_v2.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"delete_all_versions"},
+				Config:                  config,
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretV2_fakeVaultDeleteAllVersions(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(s *terraform.State) error {
+			if f.Read("secret/metadata/app/config") != nil {
+				return fmt.Errorf("expected the secret's metadata to be deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_kv_secret_v2" "test" {
+  mount               = "secret"
+  name                = "app/config"
+  data_json           = "{\"zip\": \"zap\"}"
+  delete_all_versions = true
+}
+`,
+				Check: testResourceGenericSecret_fakeVaultCheck(f, "secret/data/app/config", "zap"),
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretV2_fakeVaultCAS(t *testing.T) {
+	f := newFakeVault(t)
+	f.Write("secret/data/app/config", map[string]interface{}{
+		"data": map[string]interface{}{"zip": "existing"},
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				// cas = 0 only writes a secret that doesn't exist yet.
+				Config: f.ProviderConfig() + `
+resource "vault_kv_secret_v2" "test" {
+  mount     = "secret"
+  name      = "app/config"
+  data_json = "{\"zip\": \"zap\"}"
+  cas       = 0
+}
+`,
+				ExpectError: regexp.MustCompile("check-and-set parameter did not match the current version"),
+			},
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_kv_secret_v2" "test" {
+  mount     = "secret"
+  name      = "app/config"
+  data_json = "{\"zip\": \"zap\"}"
+  cas       = 1
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_secret_v2.test", "version", "2"),
+					testResourceGenericSecret_fakeVaultCheck(f, "secret/data/app/config", "zap"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceKVSecretV2_fakeVaultNotKVv2(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("kv", "kv", nil)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_kv_secret_v2" "test" {
+  mount     = "kv"
+  name      = "app/config"
+  data_json = "{\"zip\": \"zap\"}"
+}
+`,
+				ExpectError: regexp.MustCompile(`"kv" is not the path of a KV version 2 mount`),
+			},
+		},
+	})
+
+	if n := f.RequestCount("PUT", "kv/data/app/config"); n != 0 {
+		t.Errorf("expected nothing to be written, got %d requests", n)
+	}
+}
@@ -0,0 +1,392 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/vault/api"
+)
+
+func kvTreeResource() *schema.Resource {
+	return &schema.Resource{
+		Create: kvTreeWrite,
+		Update: kvTreeWrite,
+		Delete: kvTreeDelete,
+		Read:   kvTreeRead,
+		Importer: &schema.ResourceImporter{
+			State: kvTreeImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Full path, including the mount, under which the secrets are written.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"secrets": {
+				Type:         schema.TypeMap,
+				Required:     true,
+				Description:  "JSON-encoded data of each secret, by path relative to path.",
+				ValidateFunc: validateKVTreeSecrets,
+				Sensitive:    true,
+			},
+
+			"prune": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Delete the secrets under path that aren't in secrets.",
+			},
+		},
+	}
+}
+
+func validateKVTreeSecrets(v interface{}, k string) ([]string, []error) {
+	var errs []error
+	for name, dataJSON := range v.(map[string]interface{}) {
+		if name == "" || strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.Contains(name, "//") {
+			errs = append(errs, fmt.Errorf("%s: %q is not a relative path to a secret", k, name))
+			continue
+		}
+		s, ok := dataJSON.(string)
+		if !ok {
+			// Not known until apply.
+			continue
+		}
+		if _, es := ValidateDataJSON(s, k); len(es) > 0 {
+			errs = append(errs, fmt.Errorf("%s: data of %q is not a JSON object: %s", k, name, es[0]))
+		}
+	}
+	return nil, errs
+}
+
+// kvTree is the KV mount of a vault_kv_tree.
+type kvTree struct {
+	client    *api.Client
+	path      string
+	mountPath string
+	v2        bool
+}
+
+func newKVTree(client *api.Client, path string, serverVersion *version.Version) (*kvTree, error) {
+	mountPath, v2, err := isKVv2(path, client, serverVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error determining if it's a v2 path: %s", err)
+	}
+	return &kvTree{client: client, path: path, mountPath: mountPath, v2: v2}, nil
+}
+
+// secretPath returns the path to read, write and delete the data of the
+// secret at name, relative to the tree's path.
+func (t *kvTree) secretPath(name string) string {
+	p := t.path + "/" + name
+	if t.v2 {
+		p = addPrefixToVKVPath(p, t.mountPath, "data")
+	}
+	return p
+}
+
+// list returns the paths of the secrets under the tree's path, relative to
+// it.
+func (t *kvTree) list() ([]string, error) {
+	keys, err := kvListRecursive(t.client, t.path, t.mountPath, t.v2, 0)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, k := range keys {
+		if !strings.HasSuffix(k, "/") {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// read returns the data of the secret at name, or nil if it has none. A
+// KV version 2 secret whose latest version is deleted is still listed, but
+// has no data.
+func (t *kvTree) read(name string) (map[string]interface{}, error) {
+	p := t.secretPath(name)
+	secret, err := kvReadRequest(t.client, p, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q from Vault: %s", p, err)
+	}
+	if secret == nil {
+		return nil, nil
+	}
+	if !t.v2 {
+		return secret.Data, nil
+	}
+	data, _ := secret.Data["data"].(map[string]interface{})
+	return data, nil
+}
+
+func (t *kvTree) write(name, dataJSON string) error {
+	p := t.secretPath(name)
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
+		return fmt.Errorf("data of %q syntax error: %s", name, err)
+	}
+	if t.v2 {
+		data = map[string]interface{}{
+			"data":    data,
+			"options": map[string]interface{}{},
+		}
+	}
+
+	log.Printf("[DEBUG] Writing KV secret to %q", p)
+	if _, err := t.client.Logical().Write(p, data); err != nil {
+		return fmt.Errorf("error writing %q to Vault: %s", p, err)
+	}
+	log.Printf("[DEBUG] Wrote KV secret to %q", p)
+	return nil
+}
+
+func (t *kvTree) delete(name string) error {
+	p := t.secretPath(name)
+
+	log.Printf("[DEBUG] Deleting KV secret %q", p)
+	if _, err := t.client.Logical().Delete(p); err != nil {
+		return fmt.Errorf("error deleting %q from Vault: %s", p, err)
+	}
+	log.Printf("[DEBUG] Deleted KV secret %q", p)
+	return nil
+}
+
+func kvTreeWrite(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := strings.Trim(d.Get("path").(string), "/")
+	tree, err := newKVTree(client, path, meta.(*ProviderMeta).ServerVersion())
+	if err != nil {
+		return err
+	}
+
+	o, n := d.GetChange("secrets")
+	oldSecrets := o.(map[string]interface{})
+	newSecrets := n.(map[string]interface{})
+
+	// If a write or delete fails, the state records the secrets as they
+	// were left in Vault, so that the next apply picks up where this one
+	// stopped.
+	d.SetId(path)
+	d.Partial(true)
+	d.SetPartial("path")
+	d.SetPartial("prune")
+	written := map[string]interface{}{}
+	for name, dataJSON := range oldSecrets {
+		written[name] = dataJSON
+	}
+	fail := func(err error) error {
+		d.Set("secrets", written)
+		d.SetPartial("secrets")
+		return err
+	}
+
+	var names []string
+	for name := range newSecrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dataJSON := newSecrets[name].(string)
+		if old, ok := oldSecrets[name]; ok && old.(string) == dataJSON {
+			continue
+		}
+		if err := tree.write(name, dataJSON); err != nil {
+			return fail(err)
+		}
+		written[name] = dataJSON
+	}
+
+	removed := map[string]bool{}
+	for name := range oldSecrets {
+		if _, ok := newSecrets[name]; !ok {
+			removed[name] = true
+		}
+	}
+	// Secrets written since the last refresh aren't in the state yet. KV
+	// version 2 secrets whose latest version is deleted or destroyed are
+	// still listed, but there's nothing left to delete.
+	if d.Get("prune").(bool) {
+		existing, err := tree.list()
+		if err != nil {
+			return fail(err)
+		}
+		for _, name := range existing {
+			if _, ok := newSecrets[name]; ok || removed[name] {
+				continue
+			}
+			data, err := tree.read(name)
+			if err != nil {
+				return fail(err)
+			}
+			if data != nil {
+				removed[name] = true
+			}
+		}
+	}
+	names = names[:0]
+	for name := range removed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := tree.delete(name); err != nil {
+			return fail(err)
+		}
+		delete(written, name)
+	}
+
+	d.Partial(false)
+
+	return kvTreeRead(d, meta)
+}
+
+func kvTreeRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+	tree, err := newKVTree(client, path, meta.(*ProviderMeta).ServerVersion())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Listing KV secrets under %q", path)
+	existing, err := tree.list()
+	if err != nil {
+		return err
+	}
+
+	// Only managed secrets are read, unless unmanaged ones are pruned, in
+	// which case they're added to the state so that the plan deletes them.
+	managed := d.Get("secrets").(map[string]interface{})
+	prune := d.Get("prune").(bool)
+
+	secrets := map[string]string{}
+	for _, name := range existing {
+		current, isManaged := managed[name].(string)
+		if !isManaged && !prune {
+			continue
+		}
+		data, err := tree.read(name)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			continue
+		}
+
+		// Keep the JSON as it was written, unless the data changed.
+		var currentData map[string]interface{}
+		if current != "" && json.Unmarshal([]byte(current), &currentData) == nil && reflect.DeepEqual(normalizeKVTreeData(currentData), normalizeKVTreeData(data)) {
+			secrets[name] = current
+			continue
+		}
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON for %q: %s", tree.secretPath(name), err)
+		}
+		secrets[name] = string(jsonData)
+	}
+
+	d.Set("path", path)
+	if err := d.Set("secrets", secrets); err != nil {
+		return fmt.Errorf("error setting secrets for %q: %s", path, err)
+	}
+
+	return nil
+}
+
+// normalizeKVTreeData makes data decoded from the configuration comparable
+// to data decoded from Vault, whose numbers are json.Number.
+func normalizeKVTreeData(data map[string]interface{}) map[string]interface{} {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return data
+	}
+	return normalized
+}
+
+// kvTreeDelete deletes the managed secrets. Unmanaged secrets are kept,
+// even when prune is set.
+func kvTreeDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	tree, err := newKVTree(client, d.Id(), meta.(*ProviderMeta).ServerVersion())
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range d.Get("secrets").(map[string]interface{}) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := tree.delete(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// kvTreeImport manages every secret under the imported path.
+func kvTreeImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	path := strings.Trim(d.Id(), "/")
+	tree, err := newKVTree(client, path, meta.(*ProviderMeta).ServerVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := tree.list()
+	if err != nil {
+		return nil, err
+	}
+	// The data is filled in by the refresh that follows.
+	secrets := map[string]string{}
+	for _, name := range existing {
+		secrets[name] = ""
+	}
+
+	d.SetId(path)
+	d.Set("path", path)
+	if err := d.Set("secrets", secrets); err != nil {
+		return nil, fmt.Errorf("error setting secrets for %q: %s", path, err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,328 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceKVTree(t *testing.T) {
+	mount := acctest.RandomWithPrefix("tf-test-kv")
+	resource.Test(t, resource.TestCase{
+		Providers: testProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testResourceKVSecretV2_mountConfig(mount) + testResourceKVTree_config("${vault_mount.kv.path}/apps", false, map[string]string{
+					"a":      `{"zip": "zap"}`,
+					"team/b": `{"foo": "bar"}`,
+				}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_tree.test", "path", mount+"/apps"),
+					resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.%", "2"),
+					resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.a", `{"zip": "zap"}`),
+					resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.team/b", `{"foo": "bar"}`),
+				),
+			},
+			{
+				Config: testResourceKVSecretV2_mountConfig(mount) + testResourceKVTree_config("${vault_mount.kv.path}/apps", false, map[string]string{
+					"a": `{"zip": "zoop"}`,
+				}),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.%", "1"),
+					resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.a", `{"zip": "zoop"}`),
+				),
+			},
+		},
+	})
+}
+
+func testResourceKVTree_config(path string, prune bool, secrets map[string]string) string {
+	config := fmt.Sprintf(`
+resource "vault_kv_tree" "test" {
+  path  = %q
+  prune = %t
+
+  secrets = {
+`, path, prune)
+	for name, dataJSON := range secrets {
+		config += fmt.Sprintf("    %q = %q\n", name, dataJSON)
+	}
+	return config + "  }\n}\n"
+}
+
+func testResourceKVTree_fakeVaultPath(mount string, v2 bool, name string) string {
+	if v2 {
+		return mount + "/data/" + name
+	}
+	return mount + "/" + name
+}
+
+// testResourceKVTree_fakeVaultCheck checks the value of zip in the secret at
+// name, or that the secret has no data when want is empty.
+func testResourceKVTree_fakeVaultCheck(f *fakeVault, mount string, v2 bool, name, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		path := testResourceKVTree_fakeVaultPath(mount, v2, name)
+		data := f.Read(path)
+		if v2 && data != nil {
+			data, _ = data["data"].(map[string]interface{})
+		}
+		if want == "" {
+			if data != nil {
+				return fmt.Errorf("secret %q not deleted: %v", path, data)
+			}
+			return nil
+		}
+		if data == nil {
+			return fmt.Errorf("secret %q not found", path)
+		}
+		if got := data["zip"]; got != want {
+			return fmt.Errorf("'zip' data of %q is %q; want %q", path, got, want)
+		}
+		return nil
+	}
+}
+
+func testResourceKVTree_fakeVaultWrite(f *fakeVault, mount string, v2 bool, name, value string) {
+	data := map[string]interface{}{"zip": value}
+	if v2 {
+		data = map[string]interface{}{"data": data}
+	}
+	f.Write(testResourceKVTree_fakeVaultPath(mount, v2, name), data)
+}
+
+// testResourceKVTree_fakeVaultDeleteCount checks how many times the secret
+// at name was deleted.
+func testResourceKVTree_fakeVaultDeleteCount(f *fakeVault, mount string, v2 bool, name string, want int) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		path := testResourceKVTree_fakeVaultPath(mount, v2, name)
+		if got := f.RequestCount("DELETE", path); got != want {
+			return fmt.Errorf("secret %q was deleted %d times; want %d", path, got, want)
+		}
+		return nil
+	}
+}
+
+func TestResourceKVTree_fakeVault(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		t.Run(fmt.Sprintf("v2=%t", v2), func(t *testing.T) {
+			f := newFakeVault(t)
+			mount := "secret"
+			if !v2 {
+				mount = "kv"
+				f.Mount(mount, "kv", nil)
+			}
+			testResourceKVTree_fakeVaultWrite(f, mount, v2, "apps/unmanaged", "keep")
+
+			resource.UnitTest(t, resource.TestCase{
+				Providers: f.Providers(),
+				CheckDestroy: resource.ComposeTestCheckFunc(
+					testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/a", ""),
+					testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/team/b", ""),
+					testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/unmanaged", "keep"),
+				),
+				Steps: []resource.TestStep{
+					{
+						Config: f.ProviderConfig() + testResourceKVTree_config(mount+"/apps", false, map[string]string{
+							"a":      `{"zip": "zap"}`,
+							"team/b": `{"zip": "zoop"}`,
+						}),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("vault_kv_tree.test", "path", mount+"/apps"),
+							resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.%", "2"),
+							resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.a", `{"zip": "zap"}`),
+							resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.team/b", `{"zip": "zoop"}`),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/a", "zap"),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/team/b", "zoop"),
+						),
+					},
+					{
+						// A managed secret deleted outside of Terraform.
+						PreConfig: func() {
+							f.Delete(testResourceKVTree_fakeVaultPath(mount, v2, "apps/team/b"))
+						},
+						Config: f.ProviderConfig() + testResourceKVTree_config(mount+"/apps", false, map[string]string{
+							"a":      `{"zip": "zap"}`,
+							"team/b": `{"zip": "zoop"}`,
+						}),
+						PlanOnly:           true,
+						ExpectNonEmptyPlan: true,
+					},
+					{
+						// Changed outside of Terraform.
+						PreConfig: func() {
+							testResourceKVTree_fakeVaultWrite(f, mount, v2, "apps/a", "changed")
+						},
+						Config: f.ProviderConfig() + testResourceKVTree_config(mount+"/apps", false, map[string]string{
+							"a":      `{"zip": "zap"}`,
+							"team/b": `{"zip": "zoop"}`,
+						}),
+						Check: resource.ComposeTestCheckFunc(
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/a", "zap"),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/team/b", "zoop"),
+						),
+					},
+					{
+						// Removed from the configuration.
+						Config: f.ProviderConfig() + testResourceKVTree_config(mount+"/apps", false, map[string]string{
+							"a": `{"zip": "zap"}`,
+						}),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.%", "1"),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/a", "zap"),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/team/b", ""),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/unmanaged", "keep"),
+						),
+					},
+					{
+						ResourceName:            "vault_kv_tree.test",
+						ImportState:             true,
+						ImportStateId:           mount + "/apps",
+						ImportStateVerify:       true,
+						ImportStateVerifyIgnore: []string{"secrets", "prune"},
+						Config: f.ProviderConfig() + testResourceKVTree_config(mount+"/apps", false, map[string]string{
+							"a": `{"zip": "zap"}`,
+						}),
+						ImportStateCheck: func(states []*terraform.InstanceState) error {
+							attrs := states[0].Attributes
+							if attrs["secrets.%"] != "2" || attrs["secrets.a"] != `{"zip":"zap"}` || attrs["secrets.unmanaged"] != `{"zip":"keep"}` {
+								return fmt.Errorf("bad imported secrets: %v", attrs)
+							}
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestResourceKVTree_fakeVaultPrune(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		t.Run(fmt.Sprintf("v2=%t", v2), func(t *testing.T) {
+			f := newFakeVault(t)
+			mount := "secret"
+			if !v2 {
+				mount = "kv"
+				f.Mount(mount, "kv", nil)
+			}
+			testResourceKVTree_fakeVaultWrite(f, mount, v2, "apps/unmanaged", "remove")
+			testResourceKVTree_fakeVaultWrite(f, mount, v2, "other", "keep")
+
+			resource.UnitTest(t, resource.TestCase{
+				Providers: f.Providers(),
+				CheckDestroy: resource.ComposeTestCheckFunc(
+					testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/a", ""),
+					testResourceKVTree_fakeVaultCheck(f, mount, v2, "other", "keep"),
+				),
+				Steps: []resource.TestStep{
+					{
+						Config: f.ProviderConfig() + testResourceKVTree_config(mount+"/apps", true, map[string]string{
+							"a": `{"zip": "zap"}`,
+						}),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.%", "1"),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/a", "zap"),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/unmanaged", ""),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "other", "keep"),
+						),
+					},
+					{
+						// Added outside of Terraform.
+						PreConfig: func() {
+							testResourceKVTree_fakeVaultWrite(f, mount, v2, "apps/team/extra", "remove")
+						},
+						Config: f.ProviderConfig() + testResourceKVTree_config(mount+"/apps", true, map[string]string{
+							"a": `{"zip": "zap"}`,
+						}),
+						PlanOnly:           true,
+						ExpectNonEmptyPlan: true,
+					},
+					{
+						Config: f.ProviderConfig() + testResourceKVTree_config(mount+"/apps", true, map[string]string{
+							"a": `{"zip": "zap"}`,
+						}),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.%", "1"),
+							testResourceKVTree_fakeVaultCheck(f, mount, v2, "apps/team/extra", ""),
+							// A deleted KV version 2 secret is still listed,
+							// but isn't deleted again.
+							testResourceKVTree_fakeVaultDeleteCount(f, mount, v2, "apps/unmanaged", 1),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestResourceKVTree_fakeVaultPartialWrite(t *testing.T) {
+	f := newFakeVault(t)
+	testResourceKVTree_fakeVaultWrite(f, "secret", true, "apps/c", "remove")
+	secrets := map[string]string{
+		"a": `{"zip": "zap"}`,
+		"b": `{"zip": "zoop"}`,
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					f.InjectFault(fakeVaultFault{Method: "PUT", Path: "secret/data/apps/b", Status: 403, Count: 1})
+				},
+				Config:      f.ProviderConfig() + testResourceKVTree_config("secret/apps", true, secrets),
+				ExpectError: regexp.MustCompile(`permission denied`),
+			},
+			{
+				// The secret that was written is in the state, so only the
+				// rest of the apply is planned.
+				Config:             f.ProviderConfig() + testResourceKVTree_config("secret/apps", true, secrets),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: f.ProviderConfig() + testResourceKVTree_config("secret/apps", true, secrets),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_kv_tree.test", "id", "secret/apps"),
+					resource.TestCheckResourceAttr("vault_kv_tree.test", "secrets.%", "2"),
+					testResourceKVTree_fakeVaultCheck(f, "secret", true, "apps/a", "zap"),
+					testResourceKVTree_fakeVaultCheck(f, "secret", true, "apps/b", "zoop"),
+					testResourceKVTree_fakeVaultCheck(f, "secret", true, "apps/c", ""),
+					func(*terraform.State) error {
+						if got := f.RequestCount("PUT", "secret/data/apps/a"); got != 1 {
+							return fmt.Errorf("secret %q was written %d times; want 1", "secret/data/apps/a", got)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceKVTree_invalidSecrets(t *testing.T) {
+	f := newFakeVault(t)
+	for _, tc := range []struct {
+		secrets map[string]string
+		err     string
+	}{
+		{map[string]string{"/a": `{}`}, `/a.* is not a relative path to a secret`},
+		{map[string]string{"a//b": `{}`}, `a//b.* is not a relative path to a secret`},
+		{map[string]string{"a": `[]`}, `data of .*a.* is not a JSON object`},
+	} {
+		resource.UnitTest(t, resource.TestCase{
+			Providers: f.Providers(),
+			Steps: []resource.TestStep{
+				{
+					Config:      f.ProviderConfig() + testResourceKVTree_config("secret/apps", false, tc.secrets),
+					ExpectError: regexp.MustCompile(tc.err),
+				},
+			},
+		})
+	}
+}
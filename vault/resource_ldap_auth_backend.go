@@ -6,8 +6,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 const ldapAuthType string = "ldap"
@@ -137,14 +136,17 @@ func ldapAuthBackendConfigPath(path string) string {
 }
 
 func ldapAuthBackendWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	authType := ldapAuthType
 	path := d.Get("path").(string)
 	desc := d.Get("description").(string)
 
 	log.Printf("[DEBUG] Enabling LDAP auth backend %q", path)
-	err := client.Sys().EnableAuth(path, authType, desc)
+	err = client.Sys().EnableAuth(path, authType, desc)
 	if err != nil {
 		return fmt.Errorf("error enabling ldap auth backend %q: %s", path, err)
 	}
@@ -156,7 +158,10 @@ func ldapAuthBackendWrite(d *schema.ResourceData, meta interface{}) error {
 }
 
 func ldapAuthBackendUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := ldapAuthBackendConfigPath(d.Id())
 	data := map[string]interface{}{}
@@ -226,7 +231,7 @@ func ldapAuthBackendUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	log.Printf("[DEBUG] Writing LDAP config %q", path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 
 	if err != nil {
 		d.SetId("")
@@ -238,7 +243,10 @@ func ldapAuthBackendUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func ldapAuthBackendRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	auths, err := client.Sys().ListAuth()
@@ -258,7 +266,7 @@ func ldapAuthBackendRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Reading LDAP auth backend config %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading ldap auth backend config %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read LDAP auth backend config %q", path)
@@ -292,11 +300,14 @@ func ldapAuthBackendRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func ldapAuthBackendDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting LDAP auth backend %q", path)
-	err := client.Sys().DisableAuth(path)
+	err = client.Sys().DisableAuth(path)
 	if err != nil {
 		return fmt.Errorf("error deleting ldap auth backend %q: %q", path, err)
 	}
@@ -306,12 +317,15 @@ func ldapAuthBackendDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func ldapAuthBackendExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 	path := ldapAuthBackendConfigPath(d.Id())
 
 	log.Printf("[DEBUG] Checking if LDAP auth backend %q exists", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking for existence of ldap config %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if LDAP auth backend %q exists", path)
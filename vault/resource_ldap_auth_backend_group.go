@@ -6,8 +6,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 func ldapAuthBackendGroupResource() *schema.Resource {
@@ -51,7 +50,10 @@ func ldapAuthBackendGroupResourcePath(backend, groupname string) string {
 }
 
 func ldapAuthBackendGroupResourceWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	groupname := d.Get("groupname").(string)
@@ -64,7 +66,7 @@ func ldapAuthBackendGroupResourceWrite(d *schema.ResourceData, meta interface{})
 	}
 
 	log.Printf("[DEBUG] Writing LDAP group %q", path)
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 
 	d.SetId(path)
 
@@ -78,12 +80,15 @@ func ldapAuthBackendGroupResourceWrite(d *schema.ResourceData, meta interface{})
 }
 
 func ldapAuthBackendGroupResourceRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Reading LDAP group %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading ldap group %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read LDAP group %q", path)
@@ -103,11 +108,14 @@ func ldapAuthBackendGroupResourceRead(d *schema.ResourceData, meta interface{})
 }
 
 func ldapAuthBackendGroupResourceDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting LDAP group %q", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("error deleting ldap group %q", path)
 	}
@@ -117,12 +125,15 @@ func ldapAuthBackendGroupResourceDelete(d *schema.ResourceData, meta interface{}
 }
 
 func ldapAuthBackendGroupResourceExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Checking if LDAP group %q exists", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking for existence of ldap group %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if LDAP group %q exists", path)
@@ -10,7 +10,6 @@ import (
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/hashicorp/vault/api"
 	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
@@ -45,7 +44,7 @@ func TestLDAPAuthBackendUser_basic(t *testing.T) {
 }
 
 func testLDAPAuthBackendUserDestroy(s *terraform.State) error {
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "vault_ldap_auth_backend_user" {
@@ -74,7 +73,7 @@ func testLDAPAuthBackendUserCheck_groups(backend, username string, groups []stri
 			return fmt.Errorf("resource has no primary instance")
 		}
 
-		client := testProvider.Meta().(*api.Client)
+		client := testProvider.Meta().(*ProviderMeta).GetClient()
 		resp, err := client.Logical().Read(instanceState.ID)
 		if err != nil {
 			return err
@@ -125,7 +124,7 @@ func testLDAPAuthBackendUserCheck_attrs(backend, username string) resource.TestC
 			return fmt.Errorf("expected ID to be %q, got %q instead", endpoint, instanceState.ID)
 		}
 
-		client := testProvider.Meta().(*api.Client)
+		client := testProvider.Meta().(*ProviderMeta).GetClient()
 		authMounts, err := client.Sys().ListAuth()
 		if err != nil {
 			return err
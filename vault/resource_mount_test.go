@@ -2,6 +2,8 @@ package vault
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
@@ -174,7 +176,7 @@ func testResourceMount_updateCheck(s *terraform.State) error {
 }
 
 func findMount(path string) (*api.MountOutput, error) {
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 
 	path = path + "/"
 
@@ -189,3 +191,88 @@ func findMount(path string) (*api.MountOutput, error) {
 
 	return nil, fmt.Errorf("unable to find mount %s in Vault; current list: %v", path, mounts)
 }
+
+func TestResourceMount_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(s *terraform.State) error {
+			if f.HasMount("remountingExample") {
+				return fmt.Errorf("mount still exists")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceMount_initialConfig("example"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_mount.test", "path", "example"),
+					resource.TestCheckResourceAttr("vault_mount.test", "default_lease_ttl_seconds", "3600"),
+					resource.TestCheckResourceAttr("vault_mount.test", "max_lease_ttl_seconds", "36000"),
+				),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceMount_updateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_mount.test", "path", "remountingExample"),
+					resource.TestCheckResourceAttr("vault_mount.test", "default_lease_ttl_seconds", "7200"),
+					resource.TestCheckResourceAttr("vault_mount.test", "max_lease_ttl_seconds", "72000"),
+					resource.TestCheckResourceAttrSet("vault_mount.test", "accessor"),
+					func(s *terraform.State) error {
+						if f.HasMount("example") || !f.HasMount("remountingExample") {
+							return fmt.Errorf("mount was not moved")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceMount_fakeVaultSealed(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				PreConfig:   f.Seal,
+				Config:      f.ProviderConfig() + testResourceMount_initialConfig("example"),
+				ExpectError: regexp.MustCompile("Vault is sealed"),
+			},
+			{
+				PreConfig: f.Unseal,
+				Config:    f.ProviderConfig() + testResourceMount_initialConfig("example"),
+				Check:     resource.TestCheckResourceAttr("vault_mount.test", "path", "example"),
+			},
+		},
+	})
+}
+
+func TestResourceMount_fakeVaultServerError(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("example", "kv", map[string]string{"version": "1"})
+
+	d := mountResource().TestResourceData()
+	d.SetId("example")
+
+	// Errors that outlast the retries are reported, and the mount is kept
+	// in state.
+	f.InjectFault(fakeVaultFault{Method: "GET", Path: "sys/mounts", Status: 500})
+	if err := mountRead(d, f.Meta()); err == nil || !strings.Contains(err.Error(), "Code: 500") {
+		t.Fatalf("expected a server error, got %v", err)
+	}
+	if d.Id() != "example" {
+		t.Fatalf("expected the mount to stay in state")
+	}
+
+	// A mount that is really gone is removed from state.
+	f.ClearFaults()
+	f.Delete("sys/mounts/example")
+	if err := mountRead(d, f.Meta()); err != nil {
+		t.Fatal(err)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected the mount to be removed from state")
+	}
+}
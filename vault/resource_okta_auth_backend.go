@@ -197,7 +197,10 @@ func oktaAuthBackendResource() *schema.Resource {
 }
 
 func oktaAuthBackendWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	authType := oktaAuthType
 	desc := d.Get("description").(string)
@@ -205,7 +208,7 @@ func oktaAuthBackendWrite(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Writing auth %s to Vault", authType)
 
-	err := client.Sys().EnableAuth(path, authType, desc)
+	err = client.Sys().EnableAuth(path, authType, desc)
 
 	if err != nil {
 		return fmt.Errorf("error writing to Vault: %s", err)
@@ -217,13 +220,16 @@ func oktaAuthBackendWrite(d *schema.ResourceData, meta interface{}) error {
 }
 
 func oktaAuthBackendDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting auth %s from Vault", path)
 
-	err := client.Sys().DisableAuth(path)
+	err = client.Sys().DisableAuth(path)
 
 	if err != nil {
 		return fmt.Errorf("error disabling auth from Vault: %s", err)
@@ -233,7 +239,10 @@ func oktaAuthBackendDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func oktaAuthBackendRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Reading auth %s from Vault", path)
@@ -273,7 +282,10 @@ func oktaAuthBackendRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func oktaAuthBackendUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Updating auth %s in Vault", path)
@@ -293,7 +305,7 @@ func oktaAuthBackendUpdate(d *schema.ResourceData, meta interface{}) error {
 		configuration["max_ttl"] = maxTtl
 	}
 
-	_, err := client.Logical().Write(oktaConfigEndpoint(path), configuration)
+	_, err = client.Logical().Write(oktaConfigEndpoint(path), configuration)
 	if err != nil {
 		return fmt.Errorf("error updating configuration to Vault for path %s: %s", path, err)
 	}
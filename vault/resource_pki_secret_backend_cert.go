@@ -0,0 +1,287 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+)
+
+func pkiSecretBackendCertResource() *schema.Resource {
+	return &schema.Resource{
+		Create: pkiSecretBackendCertCreate,
+		Read:   pkiSecretBackendCertRead,
+		Update: pkiSecretBackendCertUpdate,
+		Delete: pkiSecretBackendCertDelete,
+
+		Schema: map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the PKI secrets engine is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the role to issue the certificate with.",
+			},
+
+			"common_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Common name of the certificate.",
+			},
+
+			"alt_names": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "DNS names and email addresses to include as Subject Alternative Names.",
+			},
+
+			"ip_sans": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IP addresses to include as Subject Alternative Names.",
+			},
+
+			"exclude_cn_from_sans": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Leave the common name out of the Subject Alternative Names.",
+			},
+
+			"ttl": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: util.ValidateDuration,
+				Description:  "Validity of the certificate, such as 720h. The role's TTL applies when unset.",
+			},
+
+			"min_seconds_remaining": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     604800,
+				Description: "Issue the certificate again when it expires in fewer seconds than this.",
+			},
+
+			"revoke": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Revoke the certificate when it's destroyed, including when it's issued again.",
+			},
+
+			"certificate": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded certificate.",
+			},
+
+			"issuing_ca": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded certificate of the issuing CA.",
+			},
+
+			"ca_chain": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "PEM-encoded certificates of the CA chain.",
+			},
+
+			"private_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "PEM-encoded private key of the certificate.",
+			},
+
+			"private_key_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of the private key.",
+			},
+
+			"serial": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Serial number of the certificate.",
+			},
+
+			"expiration": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Expiration of the certificate, as a Unix timestamp.",
+			},
+
+			"renew_pending": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the certificate expires within min_seconds_remaining, and will be issued again.",
+			},
+		},
+	}
+}
+
+func pkiSecretBackendCertCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	name := d.Get("name").(string)
+	path := backend + "/issue/" + name
+
+	data := map[string]interface{}{
+		"common_name":          d.Get("common_name").(string),
+		"alt_names":            pkiStringList(d.Get("alt_names")),
+		"ip_sans":              pkiStringList(d.Get("ip_sans")),
+		"exclude_cn_from_sans": d.Get("exclude_cn_from_sans").(bool),
+		"format":               "pem",
+	}
+	if v, ok := d.GetOk("ttl"); ok {
+		data["ttl"] = v.(string)
+	}
+
+	log.Printf("[DEBUG] Issuing certificate with role %q on PKI backend %q", name, backend)
+	secret, err := client.Logical().Write(path, data)
+	if err != nil {
+		return fmt.Errorf("error issuing certificate with role %q on PKI backend %q: %s", name, backend, err)
+	}
+	if secret == nil {
+		return fmt.Errorf("no response issuing certificate with role %q on PKI backend %q", name, backend)
+	}
+	log.Printf("[DEBUG] Issued certificate with role %q on PKI backend %q", name, backend)
+
+	if err := pkiSecretBackendCertSetIssued(d, path, secret.Data); err != nil {
+		return err
+	}
+	d.Set("private_key", secret.Data["private_key"])
+	d.Set("private_key_type", secret.Data["private_key_type"])
+
+	return pkiSecretBackendCertRead(d, meta)
+}
+
+// pkiSecretBackendCertSetIssued sets the ID and the attributes common to
+// issued and signed certificates from the response of Vault.
+func pkiSecretBackendCertSetIssued(d *schema.ResourceData, path string, data map[string]interface{}) error {
+	serial, _ := data["serial_number"].(string)
+	d.SetId(path + "/" + serial)
+	d.Set("certificate", data["certificate"])
+	d.Set("issuing_ca", data["issuing_ca"])
+	d.Set("ca_chain", data["ca_chain"])
+	d.Set("serial", serial)
+
+	if v, ok := data["expiration"]; ok {
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("unexpected value %q for expiration of %q", v, d.Id())
+		}
+		expiration, err := n.Int64()
+		if err != nil {
+			return fmt.Errorf("unexpected value %q for expiration of %q", v, d.Id())
+		}
+		d.Set("expiration", expiration)
+	}
+	return nil
+}
+
+// pkiSecretBackendCertRead flags the certificate to be issued again once it
+// expires within min_seconds_remaining. Vault isn't read: the certificate in
+// the state is all that's needed.
+func pkiSecretBackendCertRead(d *schema.ResourceData, meta interface{}) error {
+	cert, err := pkiParseCertificate(d.Get("certificate").(string))
+	if err != nil {
+		return fmt.Errorf("error parsing certificate of %q: %s", d.Id(), err)
+	}
+
+	minRemaining := time.Duration(d.Get("min_seconds_remaining").(int)) * time.Second
+	renewPending := time.Until(cert.NotAfter) < minRemaining
+	if renewPending {
+		log.Printf("[DEBUG] Certificate %q expires at %s, within %s, and will be issued again", d.Id(), cert.NotAfter, minRemaining)
+	}
+	d.Set("renew_pending", renewPending)
+
+	return nil
+}
+
+// pkiSecretBackendCertUpdate only stores min_seconds_remaining and revoke,
+// which apply to the certificate already issued.
+func pkiSecretBackendCertUpdate(d *schema.ResourceData, meta interface{}) error {
+	return pkiSecretBackendCertRead(d, meta)
+}
+
+func pkiSecretBackendCertDelete(d *schema.ResourceData, meta interface{}) error {
+	if !d.Get("revoke").(bool) {
+		return nil
+	}
+
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	serial := d.Get("serial").(string)
+
+	log.Printf("[DEBUG] Revoking certificate %q on PKI backend %q", serial, backend)
+	_, err = client.Logical().Write(backend+"/revoke", map[string]interface{}{
+		"serial_number": serial,
+	})
+	if err != nil {
+		return fmt.Errorf("error revoking certificate %q on PKI backend %q: %s", serial, backend, err)
+	}
+	log.Printf("[DEBUG] Revoked certificate %q on PKI backend %q", serial, backend)
+
+	return nil
+}
+
+// planPKICertRenewal replaces a certificate that its last refresh flagged
+// with renew_pending, so that it's revoked, when set to be, and issued or
+// signed again. Like a ForceNew argument would, it plans the certificate
+// from scratch and marks the attribute that caused the replacement.
+func planPKICertRenewal(p *schema.Provider, info *terraform.InstanceInfo, s *terraform.InstanceState, c *terraform.ResourceConfig, diff *terraform.InstanceDiff) (*terraform.InstanceDiff, error) {
+	if (info.Type != "vault_pki_secret_backend_cert" && info.Type != "vault_pki_secret_backend_sign") || s == nil || s.ID == "" || s.Attributes["renew_pending"] != "true" {
+		return diff, nil
+	}
+	if diff != nil && (diff.RequiresNew() || diff.GetDestroy()) {
+		return diff, nil
+	}
+
+	renewal, err := p.Diff(info, nil, c)
+	if err != nil {
+		return nil, err
+	}
+	for k, attr := range renewal.Attributes {
+		attr.RequiresNew = false
+		attr.Old = s.Attributes[k]
+	}
+	renewal.SetAttribute("renew_pending", &terraform.ResourceAttrDiff{
+		Old:         "true",
+		NewComputed: true,
+		RequiresNew: true,
+	})
+	return renewal, nil
+}
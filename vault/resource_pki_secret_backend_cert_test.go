@@ -0,0 +1,226 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPKISecretBackendCert_basic(t *testing.T) {
+	backend := acctest.RandomWithPrefix("tf-test-pki")
+	resource.Test(t, resource.TestCase{
+		Providers: testProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testPKISecretBackend_mountConfig("test", backend) +
+					testPKISecretBackendCert_config("${vault_mount.test.path}", `
+  ttl                   = "1h"
+  min_seconds_remaining = 60
+  revoke                = true
+`),
+				Check: testPKISecretBackendCert_check(time.Hour),
+			},
+		},
+	})
+}
+
+// testPKISecretBackendCert_roleConfig returns the configuration of a root
+// CA and a role on backend, with the role's extra attributes.
+func testPKISecretBackendCert_roleConfig(backend, extra string) string {
+	return testResourcePKISecretBackendRootCert_config(backend, "internal", "") + `
+resource "vault_pki_secret_backend_role" "test" {
+  backend          = "${vault_pki_secret_backend_root_cert.test.backend}"
+  name             = "web"
+  allowed_domains  = ["example.com"]
+  allow_subdomains = true
+  key_type         = "ec"
+  key_bits         = 256
+` + extra + `}
+`
+}
+
+// testPKISecretBackendCert_config returns the configuration of a root CA and
+// a role on backend, and of a certificate issued with them.
+func testPKISecretBackendCert_config(backend, extra string) string {
+	return testPKISecretBackendCert_roleConfig(backend, "") + `
+resource "vault_pki_secret_backend_cert" "test" {
+  backend     = "${vault_pki_secret_backend_role.test.backend}"
+  name        = "${vault_pki_secret_backend_role.test.name}"
+  common_name = "www.example.com"
+  alt_names   = ["api.example.com"]
+` + extra + `}
+`
+}
+
+// testPKISecretBackendCert_check checks that the certificate in the state
+// was issued by the root for about ttl, with the attributes matching it.
+func testPKISecretBackendCert_check(ttl time.Duration) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		resources := s.RootModule().Resources
+		attrs := resources["vault_pki_secret_backend_cert.test"].Primary.Attributes
+		cert, err := pkiParseCertificate(attrs["certificate"])
+		if err != nil {
+			return err
+		}
+		root, err := pkiParseCertificate(resources["vault_pki_secret_backend_root_cert.test"].Primary.Attributes["certificate"])
+		if err != nil {
+			return err
+		}
+
+		if err := cert.CheckSignatureFrom(root); err != nil {
+			return fmt.Errorf("certificate isn't signed by the root: %s", err)
+		}
+		if cert.Subject.CommonName != "www.example.com" || len(cert.DNSNames) != 2 {
+			return fmt.Errorf("unexpected common name %q and DNS names %v", cert.Subject.CommonName, cert.DNSNames)
+		}
+		if validity := cert.NotAfter.Sub(cert.NotBefore); validity < ttl || validity > ttl+time.Minute {
+			return fmt.Errorf("certificate is valid for %s; want %s", validity, ttl)
+		}
+		if serial := pkiCertificateSerial(cert); attrs["serial"] != serial {
+			return fmt.Errorf("serial is %q; want %q", attrs["serial"], serial)
+		}
+		if expiration := strconv.FormatInt(cert.NotAfter.Unix(), 10); attrs["expiration"] != expiration {
+			return fmt.Errorf("expiration is %q; want %q", attrs["expiration"], expiration)
+		}
+		if attrs["issuing_ca"] != resources["vault_pki_secret_backend_root_cert.test"].Primary.Attributes["certificate"] {
+			return fmt.Errorf("issuing_ca isn't the root certificate")
+		}
+		if attrs["ca_chain.#"] == "0" {
+			return fmt.Errorf("no CA chain")
+		}
+		if attrs["private_key"] == "" || attrs["private_key_type"] != "ec" {
+			return fmt.Errorf("unexpected private key of type %q", attrs["private_key_type"])
+		}
+		return nil
+	}
+}
+
+// testPKISecretBackendCert_fakeVaultRevoked checks whether the certificate
+// with the serial is revoked.
+func testPKISecretBackendCert_fakeVaultRevoked(f *fakeVault, serial *string, revoked bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		data := f.Read("pki/cert/" + *serial)
+		if data == nil {
+			return fmt.Errorf("certificate %q not found", *serial)
+		}
+		if got := data["revocation_time"].(int64) != 0; got != revoked {
+			return fmt.Errorf("certificate %q revoked: %t; want %t", *serial, got, revoked)
+		}
+		return nil
+	}
+}
+
+// testPKISecretBackendCert_serial stores the serial of the certificate of
+// the resource.
+func testPKISecretBackendCert_serial(name string, serial *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		*serial = s.RootModule().Resources[name].Primary.Attributes["serial"]
+		return nil
+	}
+}
+
+func TestPKISecretBackendCert_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki", "pki", nil)
+
+	var first, second string
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testPKISecretBackendCert_config("pki", `
+  ttl                   = "1h"
+  min_seconds_remaining = 60
+  revoke                = true
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testPKISecretBackendCert_check(time.Hour),
+					resource.TestMatchResourceAttr("vault_pki_secret_backend_cert.test", "id", regexp.MustCompile(`^pki/issue/web/[0-9a-f:]+$`)),
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_cert.test", "renew_pending", "false"),
+					testPKISecretBackendCert_serial("vault_pki_secret_backend_cert.test", &first),
+				),
+			},
+			{
+				// Within the renewal window, the certificate is flagged on
+				// refresh and planned to be issued again.
+				Config: f.ProviderConfig() + testPKISecretBackendCert_config("pki", `
+  ttl                   = "1h"
+  min_seconds_remaining = 7200
+  revoke                = true
+`),
+				ExpectNonEmptyPlan: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_cert.test", "renew_pending", "true"),
+					resource.TestCheckResourceAttrPtr("vault_pki_secret_backend_cert.test", "serial", &first),
+					testPKISecretBackendCert_fakeVaultRevoked(f, &first, false),
+				),
+			},
+			{
+				// The flagged certificate is revoked and issued again.
+				Config: f.ProviderConfig() + testPKISecretBackendCert_config("pki", `
+  ttl                   = "1h"
+  min_seconds_remaining = 60
+  revoke                = true
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testPKISecretBackendCert_check(time.Hour),
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_cert.test", "renew_pending", "false"),
+					testPKISecretBackendCert_serial("vault_pki_secret_backend_cert.test", &second),
+					func(*terraform.State) error {
+						if second == first {
+							return fmt.Errorf("certificate not issued again")
+						}
+						return nil
+					},
+					testPKISecretBackendCert_fakeVaultRevoked(f, &first, true),
+					testPKISecretBackendCert_fakeVaultRevoked(f, &second, false),
+				),
+			},
+			{
+				// The replaced certificate is revoked as its state says,
+				// but the new one isn't revoked when it's destroyed.
+				Config: f.ProviderConfig() + testPKISecretBackendCert_config("pki", `
+  ttl                   = "2h"
+  min_seconds_remaining = 60
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testPKISecretBackendCert_check(2*time.Hour),
+					testPKISecretBackendCert_fakeVaultRevoked(f, &second, true),
+					testPKISecretBackendCert_serial("vault_pki_secret_backend_cert.test", &first),
+				),
+			},
+		},
+		CheckDestroy: testPKISecretBackendCert_fakeVaultRevoked(f, &first, false),
+	})
+}
+
+func TestPKISecretBackendCert_fakeVaultNotAllowed(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki", "pki", nil)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testPKISecretBackendCert_config("pki", `
+  ip_sans = ["127.0.0.1"]
+  ttl     = "1h"
+`) + `
+resource "vault_pki_secret_backend_cert" "other" {
+  backend     = "${vault_pki_secret_backend_role.test.backend}"
+  name        = "${vault_pki_secret_backend_role.test.name}"
+  common_name = "www.example.org"
+}
+`,
+				ExpectError: regexp.MustCompile(`(?s)error issuing certificate with role .*web.* on PKI backend .*common name www.example.org not allowed by this role`),
+			},
+		},
+	})
+}
@@ -0,0 +1,185 @@
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func pkiSecretBackendIntermediateCertRequestResource() *schema.Resource {
+	return &schema.Resource{
+		Create: pkiSecretBackendIntermediateCertRequestCreate,
+		Read:   pkiSecretBackendIntermediateCertRequestRead,
+		Delete: pkiSecretBackendIntermediateCertRequestDelete,
+
+		Schema: map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the PKI secrets engine is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"internal", "exported"}, false),
+				Description:  "Type of intermediate to generate: exported returns the private key, internal keeps it in Vault.",
+			},
+
+			"common_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Common name of the intermediate CA.",
+			},
+
+			"alt_names": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "DNS names and email addresses to include as Subject Alternative Names.",
+			},
+
+			"ip_sans": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IP addresses to include as Subject Alternative Names.",
+			},
+
+			"exclude_cn_from_sans": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Leave the common name out of the Subject Alternative Names.",
+			},
+
+			"key_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "rsa",
+				ValidateFunc: validation.StringInSlice([]string{"rsa", "ec"}, false),
+				Description:  "Type of the key to generate, rsa or ec.",
+			},
+
+			"key_bits": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     2048,
+				Description: "Number of bits of the key to generate.",
+			},
+
+			"ou": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Organizational unit of the intermediate CA.",
+			},
+
+			"organization": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Organization of the intermediate CA.",
+			},
+
+			"csr": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded certificate signing request.",
+			},
+
+			"private_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "PEM-encoded private key of the intermediate CA, when the type is exported.",
+			},
+
+			"private_key_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of the private key, when the type is exported.",
+			},
+		},
+	}
+}
+
+func pkiSecretBackendIntermediateCertRequestCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	path := backend + "/intermediate/generate/" + d.Get("type").(string)
+
+	data := map[string]interface{}{
+		"common_name":          d.Get("common_name").(string),
+		"alt_names":            pkiStringList(d.Get("alt_names")),
+		"ip_sans":              pkiStringList(d.Get("ip_sans")),
+		"exclude_cn_from_sans": d.Get("exclude_cn_from_sans").(bool),
+		"key_type":             d.Get("key_type").(string),
+		"key_bits":             d.Get("key_bits").(int),
+		"ou":                   d.Get("ou").(string),
+		"organization":         d.Get("organization").(string),
+		"format":               "pem",
+	}
+
+	log.Printf("[DEBUG] Generating intermediate CA request on PKI backend %q", backend)
+	secret, err := client.Logical().Write(path, data)
+	if err != nil {
+		return fmt.Errorf("error generating intermediate CA request on PKI backend %q: %s", backend, err)
+	}
+	if secret == nil {
+		return fmt.Errorf("no response generating intermediate CA request on PKI backend %q", backend)
+	}
+	log.Printf("[DEBUG] Generated intermediate CA request on PKI backend %q", backend)
+
+	d.SetId(path)
+	d.Set("csr", secret.Data["csr"])
+	d.Set("private_key", secret.Data["private_key"])
+	d.Set("private_key_type", secret.Data["private_key_type"])
+
+	return pkiSecretBackendIntermediateCertRequestRead(d, meta)
+}
+
+// pkiSecretBackendIntermediateCertRequestRead does nothing: Vault doesn't
+// return the request or the pending key once they are generated.
+func pkiSecretBackendIntermediateCertRequestRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+// pkiSecretBackendIntermediateCertRequestDelete deletes the CA of the
+// backend, which is the key that the request was generated with and, once
+// it is set, the signed certificate.
+func pkiSecretBackendIntermediateCertRequestDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+
+	log.Printf("[DEBUG] Deleting intermediate CA from PKI backend %q", backend)
+	if _, err := client.Logical().Delete(backend + "/root"); err != nil {
+		return fmt.Errorf("error deleting intermediate CA from PKI backend %q: %s", backend, err)
+	}
+	log.Printf("[DEBUG] Deleted intermediate CA from PKI backend %q", backend)
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func pkiSecretBackendIntermediateSetSignedResource() *schema.Resource {
+	return &schema.Resource{
+		Create: pkiSecretBackendIntermediateSetSignedCreate,
+		Read:   pkiSecretBackendIntermediateSetSignedRead,
+		Delete: pkiSecretBackendIntermediateSetSignedDelete,
+
+		Schema: map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the PKI secrets engine of the intermediate CA is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"certificate": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validatePKICertificate,
+				Description:  "PEM-encoded signed certificate of the intermediate CA.",
+			},
+
+			"serial": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Serial number of the intermediate certificate.",
+			},
+		},
+	}
+}
+
+func validatePKICertificate(v interface{}, k string) ([]string, []error) {
+	if _, err := pkiParseCertificate(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+func pkiSecretBackendIntermediateSetSignedCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	path := backend + "/intermediate/set-signed"
+
+	cert, err := pkiParseCertificate(d.Get("certificate").(string))
+	if err != nil {
+		return fmt.Errorf("error parsing certificate: %s", err)
+	}
+
+	log.Printf("[DEBUG] Setting signed intermediate CA certificate on PKI backend %q", backend)
+	_, err = client.Logical().Write(path, map[string]interface{}{
+		"certificate": d.Get("certificate").(string),
+	})
+	if err != nil {
+		return fmt.Errorf("error setting signed intermediate CA certificate on PKI backend %q: %s", backend, err)
+	}
+	log.Printf("[DEBUG] Set signed intermediate CA certificate on PKI backend %q", backend)
+
+	d.SetId(path)
+	d.Set("serial", pkiCertificateSerial(cert))
+
+	return pkiSecretBackendIntermediateSetSignedRead(d, meta)
+}
+
+// pkiSecretBackendIntermediateSetSignedRead removes the certificate from
+// the state when it's no longer the CA certificate of the backend.
+func pkiSecretBackendIntermediateSetSignedRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	serial, err := pkiSecretBackendCASerial(client, backend)
+	if err != nil {
+		return err
+	}
+	if serial != d.Get("serial").(string) {
+		log.Printf("[WARN] CA of PKI backend %q is %q, not the intermediate %q, removing from state", backend, serial, d.Get("serial"))
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+// pkiSecretBackendIntermediateSetSignedDelete only removes the certificate
+// from the state. The intermediate CA, with its key, is deleted along with
+// the vault_pki_secret_backend_intermediate_cert_request it was requested
+// by.
+func pkiSecretBackendIntermediateSetSignedDelete(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
@@ -0,0 +1,215 @@
+package vault
+
+import (
+	"crypto/x509"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourcePKISecretBackendIntermediate(t *testing.T) {
+	rootMount := acctest.RandomWithPrefix("tf-test-pki-root")
+	intMount := acctest.RandomWithPrefix("tf-test-pki-int")
+	resource.Test(t, resource.TestCase{
+		Providers: testProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testPKISecretBackend_mountConfig("root", rootMount) +
+					testPKISecretBackend_mountConfig("intermediate", intMount) +
+					testResourcePKISecretBackendRootCert_config("${vault_mount.root.path}", "internal", "") +
+					testResourcePKISecretBackendIntermediate_config("${vault_mount.intermediate.path}", 256),
+				Check: testResourcePKISecretBackendIntermediate_check,
+			},
+		},
+	})
+}
+
+func testResourcePKISecretBackendIntermediate_config(backend string, keyBits int) string {
+	return fmt.Sprintf(`
+resource "vault_pki_secret_backend_intermediate_cert_request" "test" {
+  backend     = %q
+  type        = "internal"
+  common_name = "Test Intermediate CA"
+  key_type    = "ec"
+  key_bits    = %d
+}
+
+resource "vault_pki_secret_backend_root_sign_intermediate" "test" {
+  backend               = "${vault_pki_secret_backend_root_cert.test.backend}"
+  csr                   = "${vault_pki_secret_backend_intermediate_cert_request.test.csr}"
+  common_name           = "Test Intermediate CA"
+  ttl                   = "43800h"
+  max_path_length       = 0
+  permitted_dns_domains = ["example.com"]
+}
+
+resource "vault_pki_secret_backend_intermediate_set_signed" "test" {
+  backend     = "${vault_pki_secret_backend_intermediate_cert_request.test.backend}"
+  certificate = "${vault_pki_secret_backend_root_sign_intermediate.test.certificate}"
+}
+`, backend, keyBits)
+}
+
+// testResourcePKISecretBackendIntermediate_check checks that the
+// intermediate certificate is signed by the root and set on its backend.
+func testResourcePKISecretBackendIntermediate_check(s *terraform.State) error {
+	resources := s.RootModule().Resources
+	root, err := pkiParseCertificate(resources["vault_pki_secret_backend_root_cert.test"].Primary.Attributes["certificate"])
+	if err != nil {
+		return fmt.Errorf("error parsing root certificate: %s", err)
+	}
+	signed := resources["vault_pki_secret_backend_root_sign_intermediate.test"].Primary.Attributes
+	intermediate, err := pkiParseCertificate(signed["certificate"])
+	if err != nil {
+		return fmt.Errorf("error parsing intermediate certificate: %s", err)
+	}
+
+	if err := intermediate.CheckSignatureFrom(root); err != nil {
+		return fmt.Errorf("intermediate certificate isn't signed by the root: %s", err)
+	}
+	if !intermediate.IsCA || intermediate.Subject.CommonName != "Test Intermediate CA" || !intermediate.MaxPathLenZero {
+		return fmt.Errorf("unexpected intermediate certificate: CA %t, common name %q, max path length %d", intermediate.IsCA, intermediate.Subject.CommonName, intermediate.MaxPathLen)
+	}
+	if signed["issuing_ca"] != resources["vault_pki_secret_backend_root_cert.test"].Primary.Attributes["certificate"] {
+		return fmt.Errorf("issuing_ca isn't the root certificate")
+	}
+	if serial := pkiCertificateSerial(intermediate); signed["serial"] != serial {
+		return fmt.Errorf("serial is %q; want %q", signed["serial"], serial)
+	}
+	if serial := resources["vault_pki_secret_backend_intermediate_set_signed.test"].Primary.Attributes["serial"]; serial != signed["serial"] {
+		return fmt.Errorf("set serial is %q; want %q", serial, signed["serial"])
+	}
+	return nil
+}
+
+// testResourcePKISecretBackendIntermediate_fakeVaultCA checks that the CA of
+// the intermediate backend is the signed intermediate certificate, and
+// returns it.
+func testResourcePKISecretBackendIntermediate_fakeVaultCA(f *fakeVault, backend string, got **x509.Certificate) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		data := f.Read(backend + "/cert/ca")
+		if data == nil {
+			return fmt.Errorf("PKI backend %q has no CA", backend)
+		}
+		cert, err := pkiParseCertificate(data["certificate"].(string))
+		if err != nil {
+			return err
+		}
+		want := s.RootModule().Resources["vault_pki_secret_backend_root_sign_intermediate.test"].Primary.Attributes["serial"]
+		if serial := pkiCertificateSerial(cert); serial != want {
+			return fmt.Errorf("CA of %q is %q; want %q", backend, serial, want)
+		}
+		if got != nil {
+			*got = cert
+		}
+		return nil
+	}
+}
+
+func TestResourcePKISecretBackendIntermediate_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki-root", "pki", nil)
+	f.Mount("pki-int", "pki", nil)
+
+	config := f.ProviderConfig() + testResourcePKISecretBackendRootCert_config("pki-root", "internal", "")
+	var first, second *x509.Certificate
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(*terraform.State) error {
+			if ca := f.Read("pki-int/cert/ca"); ca != nil {
+				return fmt.Errorf("intermediate CA not deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config + testResourcePKISecretBackendIntermediate_config("pki-int", 256),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_intermediate_cert_request.test", "id", "pki-int/intermediate/generate/internal"),
+					resource.TestMatchResourceAttr("vault_pki_secret_backend_intermediate_cert_request.test", "csr", regexp.MustCompile(`^-----BEGIN CERTIFICATE REQUEST-----`)),
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_intermediate_cert_request.test", "private_key", ""),
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_intermediate_set_signed.test", "id", "pki-int/intermediate/set-signed"),
+					testResourcePKISecretBackendIntermediate_check,
+					testResourcePKISecretBackendIntermediate_fakeVaultCA(f, "pki-int", &first),
+				),
+			},
+			{
+				// A key parameter generates a new request, which is signed
+				// and set in turn.
+				Config: config + testResourcePKISecretBackendIntermediate_config("pki-int", 384),
+				Check: resource.ComposeTestCheckFunc(
+					testResourcePKISecretBackendIntermediate_check,
+					testResourcePKISecretBackendIntermediate_fakeVaultCA(f, "pki-int", &second),
+					func(*terraform.State) error {
+						if first.SerialNumber.Cmp(second.SerialNumber) == 0 {
+							return fmt.Errorf("intermediate CA not replaced")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// The root replaced outside of Terraform.
+				PreConfig: func() {
+					f.Write("pki-root/root/generate/internal", map[string]interface{}{"common_name": "Other CA", "key_type": "ec"})
+				},
+				Config:             config + testResourcePKISecretBackendIntermediate_config("pki-int", 384),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestResourcePKISecretBackendIntermediateSetSigned_fakeVaultKeyMismatch(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki-root", "pki", nil)
+	f.Mount("pki-int", "pki", nil)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				// The root certificate wasn't requested by pki-int.
+				Config: f.ProviderConfig() + testResourcePKISecretBackendRootCert_config("pki-root", "internal", "") + `
+resource "vault_pki_secret_backend_intermediate_cert_request" "test" {
+  backend     = "pki-int"
+  type        = "exported"
+  common_name = "Test Intermediate CA"
+  key_type    = "ec"
+  key_bits    = 256
+}
+
+resource "vault_pki_secret_backend_intermediate_set_signed" "test" {
+  backend     = "${vault_pki_secret_backend_intermediate_cert_request.test.backend}"
+  certificate = "${vault_pki_secret_backend_root_cert.test.certificate}"
+}
+`,
+				ExpectError: regexp.MustCompile(`(?s)error setting signed intermediate CA certificate on PKI backend .*public key of certificate does not match private key`),
+			},
+		},
+	})
+}
+
+func TestResourcePKISecretBackendIntermediateSetSigned_invalidCertificate(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_pki_secret_backend_intermediate_set_signed" "test" {
+  backend     = "pki-int"
+  certificate = "not a certificate"
+}
+`,
+				ExpectError: regexp.MustCompile(`certificate: no PEM-encoded certificate found`),
+			},
+		},
+	})
+}
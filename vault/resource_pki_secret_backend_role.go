@@ -0,0 +1,381 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+)
+
+// pkiSecretBackendRoleBoolFields are the boolean options of a role, which
+// are read back and written as they are.
+var pkiSecretBackendRoleBoolFields = []string{
+	"allow_localhost",
+	"allow_bare_domains",
+	"allow_subdomains",
+	"allow_glob_domains",
+	"allow_any_name",
+	"enforce_hostnames",
+	"allow_ip_sans",
+	"server_flag",
+	"client_flag",
+	"code_signing_flag",
+	"email_protection_flag",
+	"use_csr_common_name",
+	"use_csr_sans",
+	"generate_lease",
+	"no_store",
+	"require_cn",
+}
+
+// pkiSecretBackendRoleListFields are the list options of a role.
+var pkiSecretBackendRoleListFields = []string{
+	"allowed_domains",
+	"key_usage",
+	"ext_key_usage",
+	"ou",
+	"organization",
+}
+
+// pkiSecretBackendRoleIntFields are the numeric options of a role. Vault
+// returns the TTLs in seconds.
+var pkiSecretBackendRoleIntFields = []string{
+	"key_bits",
+	"ttl",
+	"max_ttl",
+}
+
+func pkiSecretBackendRoleResource() *schema.Resource {
+	return &schema.Resource{
+		Create: pkiSecretBackendRoleWrite,
+		Read:   pkiSecretBackendRoleRead,
+		Update: pkiSecretBackendRoleWrite,
+		Delete: pkiSecretBackendRoleDelete,
+		Exists: pkiSecretBackendRoleExists,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the PKI secrets engine is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Unique name for the role.",
+			},
+
+			"ttl": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Default TTL of the certificates issued by the role, in seconds. The engine's default lease TTL applies when 0.",
+			},
+
+			"max_ttl": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Maximum TTL of the certificates issued by the role, in seconds. The engine's maximum lease TTL applies when 0.",
+			},
+
+			"allow_localhost": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Allow localhost as a common name or SAN.",
+			},
+
+			"allowed_domains": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Domains that certificates can be issued for, as set by allow_bare_domains, allow_subdomains and allow_glob_domains.",
+			},
+
+			"allow_bare_domains": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow the allowed domains themselves as common names or SANs.",
+			},
+
+			"allow_subdomains": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow subdomains of the allowed domains, including wildcards.",
+			},
+
+			"allow_glob_domains": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow the allowed domains to contain glob patterns, such as ftp*.example.com.",
+			},
+
+			"allow_any_name": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow any common name or SAN, regardless of the allowed domains.",
+			},
+
+			"enforce_hostnames": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Only allow valid host names, or email addresses, as common names and DNS SANs.",
+			},
+
+			"allow_ip_sans": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Allow IP SANs.",
+			},
+
+			"server_flag": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Mark the certificates for server use.",
+			},
+
+			"client_flag": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Mark the certificates for client use.",
+			},
+
+			"code_signing_flag": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Mark the certificates for code signing use.",
+			},
+
+			"email_protection_flag": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Mark the certificates for email protection use.",
+			},
+
+			"key_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "rsa",
+				ValidateFunc: validation.StringInSlice([]string{"rsa", "ec", "any"}, false),
+				Description:  "Type of the keys of the certificates, rsa, ec or any. Signing CSRs with any key type requires any.",
+			},
+
+			"key_bits": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     2048,
+				Description: "Number of bits of the keys of the certificates.",
+			},
+
+			"key_usage": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Key usages of the certificates, such as DigitalSignature. Vault's defaults apply when unset.",
+			},
+
+			"ext_key_usage": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Extended key usages of the certificates, such as ServerAuth, on top of those set by the flags.",
+			},
+
+			"use_csr_common_name": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Use the common name of the CSR, rather than the common_name parameter, when signing.",
+			},
+
+			"use_csr_sans": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Use the SANs of the CSR, rather than the SAN parameters, when signing.",
+			},
+
+			"ou": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Organizational units of the certificates.",
+			},
+
+			"organization": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Organizations of the certificates.",
+			},
+
+			"generate_lease": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Create a lease for each certificate issued.",
+			},
+
+			"no_store": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Don't store the certificates issued in Vault, so that they can't be listed or revoked by serial.",
+			},
+
+			"require_cn": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Require a common name.",
+			},
+		},
+	}
+}
+
+func pkiSecretBackendRoleWrite(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	name := d.Get("name").(string)
+	path := backend + "/roles/" + name
+
+	data := map[string]interface{}{
+		"key_type": d.Get("key_type").(string),
+	}
+	for _, k := range pkiSecretBackendRoleBoolFields {
+		data[k] = d.Get(k).(bool)
+	}
+	for _, k := range pkiSecretBackendRoleIntFields {
+		data[k] = d.Get(k).(int)
+	}
+	for _, k := range pkiSecretBackendRoleListFields {
+		// Vault sets its own key usages when they're omitted.
+		if v, ok := d.GetOk(k); ok || k != "key_usage" {
+			data[k] = v
+		}
+	}
+
+	log.Printf("[DEBUG] Writing role %q on PKI backend %q", name, backend)
+	_, err = client.Logical().Write(path, data)
+	if err != nil {
+		return fmt.Errorf("error writing role %q for backend %q: %s", name, backend, err)
+	}
+	log.Printf("[DEBUG] Wrote role %q on PKI backend %q", name, backend)
+
+	d.SetId(path)
+	return pkiSecretBackendRoleRead(d, meta)
+}
+
+func pkiSecretBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+	pathPieces := strings.Split(path, "/")
+	if len(pathPieces) < 3 || pathPieces[len(pathPieces)-2] != "roles" {
+		return fmt.Errorf("invalid id %q; must be {backend}/roles/{name}", path)
+	}
+
+	log.Printf("[DEBUG] Reading role from %q", path)
+	secret, err := client.Logical().Read(path)
+	if err != nil && !util.IsNotFound(err) {
+		return fmt.Errorf("error reading role %q: %s", path, err)
+	}
+	log.Printf("[DEBUG] Read role from %q", path)
+	if secret == nil {
+		log.Printf("[WARN] Role %q not found, removing from state", path)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("backend", strings.Join(pathPieces[:len(pathPieces)-2], "/"))
+	d.Set("name", pathPieces[len(pathPieces)-1])
+	d.Set("key_type", secret.Data["key_type"])
+	for _, k := range pkiSecretBackendRoleBoolFields {
+		d.Set(k, secret.Data[k])
+	}
+	for _, k := range pkiSecretBackendRoleListFields {
+		d.Set(k, secret.Data[k])
+	}
+	for _, k := range pkiSecretBackendRoleIntFields {
+		v, ok := secret.Data[k]
+		if !ok {
+			continue
+		}
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("unexpected value %q for %s of %q", v, k, path)
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return fmt.Errorf("unexpected value %q for %s of %q", v, k, path)
+		}
+		d.Set(k, i)
+	}
+
+	return nil
+}
+
+func pkiSecretBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+	log.Printf("[DEBUG] Deleting role %q", path)
+	_, err = client.Logical().Delete(path)
+	if err != nil {
+		return fmt.Errorf("error deleting role %q: %s", path, err)
+	}
+	log.Printf("[DEBUG] Deleted role %q", path)
+	return nil
+}
+
+func pkiSecretBackendRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
+
+	path := d.Id()
+	log.Printf("[DEBUG] Checking if %q exists", path)
+	secret, err := client.Logical().Read(path)
+	if err != nil && !util.IsNotFound(err) {
+		return true, fmt.Errorf("error checking if %q exists: %s", path, err)
+	}
+	log.Printf("[DEBUG] Checked if %q exists", path)
+	return secret != nil, nil
+}
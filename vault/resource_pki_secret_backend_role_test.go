@@ -0,0 +1,233 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPKISecretBackendRole_basic(t *testing.T) {
+	backend := acctest.RandomWithPrefix("tf-test-pki")
+	name := acctest.RandomWithPrefix("role")
+	resource.Test(t, resource.TestCase{
+		Providers:    testProviders,
+		PreCheck:     func() { testAccPreCheck(t) },
+		CheckDestroy: testAccPKISecretBackendRoleCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testPKISecretBackend_mountConfig("test", backend) +
+					testPKISecretBackendRole_config("${vault_mount.test.path}", name, testPKISecretBackendRole_basic),
+				Check: testPKISecretBackendRole_checkBasic(backend, name),
+			},
+			{
+				Config: testPKISecretBackend_mountConfig("test", backend) +
+					testPKISecretBackendRole_config("${vault_mount.test.path}", name, testPKISecretBackendRole_updated),
+				Check: testPKISecretBackendRole_checkUpdated,
+			},
+			{
+				ResourceName:      "vault_pki_secret_backend_role.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccPKISecretBackendRoleCheckDestroy(s *terraform.State) error {
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vault_pki_secret_backend_role" {
+			continue
+		}
+		secret, err := client.Logical().Read(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if secret != nil {
+			return fmt.Errorf("role %q still exists", rs.Primary.ID)
+		}
+	}
+	return nil
+}
+
+const testPKISecretBackendRole_basic = `
+  allowed_domains  = ["example.com"]
+  allow_subdomains = true
+  ttl              = 3600
+  max_ttl          = 7200
+`
+
+const testPKISecretBackendRole_updated = `
+  allowed_domains    = ["example.com", "*.example.org"]
+  allow_bare_domains = true
+  allow_glob_domains = true
+  allow_localhost    = false
+  allow_ip_sans      = false
+  client_flag        = false
+  key_type           = "ec"
+  key_bits           = 256
+  key_usage          = ["DigitalSignature"]
+  ext_key_usage      = ["OCSPSigning"]
+  ou                 = ["Engineering"]
+  organization       = ["Example"]
+  require_cn         = false
+  use_csr_sans       = false
+  ttl                = 600
+`
+
+func testPKISecretBackendRole_config(backend, name, attributes string) string {
+	return fmt.Sprintf(`
+resource "vault_pki_secret_backend_role" "test" {
+  backend = %q
+  name    = %q
+%s}
+`, backend, name, attributes)
+}
+
+func testPKISecretBackendRole_checkBasic(backend, name string) resource.TestCheckFunc {
+	return resource.ComposeTestCheckFunc(
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "id", backend+"/roles/"+name),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "backend", backend),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "name", name),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allowed_domains.#", "1"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allowed_domains.0", "example.com"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_subdomains", "true"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_glob_domains", "false"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_localhost", "true"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "key_type", "rsa"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "key_bits", "2048"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "key_usage.#", "3"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "ext_key_usage.#", "0"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "ttl", "3600"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "max_ttl", "7200"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "require_cn", "true"),
+		resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "use_csr_sans", "true"),
+	)
+}
+
+var testPKISecretBackendRole_checkUpdated = resource.ComposeTestCheckFunc(
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allowed_domains.#", "2"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allowed_domains.1", "*.example.org"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_subdomains", "false"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_bare_domains", "true"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_glob_domains", "true"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_localhost", "false"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_ip_sans", "false"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "client_flag", "false"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "key_type", "ec"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "key_bits", "256"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "key_usage.#", "1"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "key_usage.0", "DigitalSignature"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "ext_key_usage.0", "OCSPSigning"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "ou.0", "Engineering"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "organization.0", "Example"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "require_cn", "false"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "use_csr_sans", "false"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "ttl", "600"),
+	resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "max_ttl", "0"),
+)
+
+func TestPKISecretBackendRole_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki", "pki", nil)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(*terraform.State) error {
+			if f.Read("pki/roles/web") != nil {
+				return fmt.Errorf("role not deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testPKISecretBackendRole_config("pki", "web", testPKISecretBackendRole_basic),
+				Check:  testPKISecretBackendRole_checkBasic("pki", "web"),
+			},
+			{
+				Config: f.ProviderConfig() + testPKISecretBackendRole_config("pki", "web", testPKISecretBackendRole_updated),
+				Check: resource.ComposeTestCheckFunc(
+					testPKISecretBackendRole_checkUpdated,
+					func(*terraform.State) error {
+						role := f.Read("pki/roles/web")
+						if role["allow_subdomains"] != false || role["key_type"] != "ec" {
+							return fmt.Errorf("role not updated: %v", role)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// A role changed outside of Terraform is detected and
+				// written back.
+				PreConfig: func() {
+					role := fakeVaultCopy(f.Read("pki/roles/web"))
+					role["allow_any_name"] = true
+					role["allowed_domains"] = []interface{}{"example.net"}
+					f.Write("pki/roles/web", role)
+				},
+				Config:             f.ProviderConfig() + testPKISecretBackendRole_config("pki", "web", testPKISecretBackendRole_updated),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: f.ProviderConfig() + testPKISecretBackendRole_config("pki", "web", testPKISecretBackendRole_updated),
+				Check: resource.ComposeTestCheckFunc(
+					testPKISecretBackendRole_checkUpdated,
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_role.test", "allow_any_name", "false"),
+				),
+			},
+			{
+				// A role deleted outside of Terraform is created again.
+				PreConfig: func() {
+					f.Delete("pki/roles/web")
+				},
+				Config: f.ProviderConfig() + testPKISecretBackendRole_config("pki", "web", testPKISecretBackendRole_updated),
+				Check: resource.ComposeTestCheckFunc(
+					testPKISecretBackendRole_checkUpdated,
+					func(*terraform.State) error {
+						if f.Read("pki/roles/web") == nil {
+							return fmt.Errorf("role not created again")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config:            f.ProviderConfig() + testPKISecretBackendRole_config("pki", "web", testPKISecretBackendRole_updated),
+				ResourceName:      "vault_pki_secret_backend_role.test",
+				ImportState:       true,
+				ImportStateId:     "pki/roles/web",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestPKISecretBackendRole_fakeVaultInvalid(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki", "pki", nil)
+	for _, tc := range []struct {
+		attributes string
+		err        string
+	}{
+		{"  ttl     = 7200\n  max_ttl = 3600\n", `(?s)error writing role .* value must be less than .* value`},
+		{"  key_type = \"ec\"\n", `unsupported bit length for EC key: 2048`},
+		{"  key_type = \"dsa\"\n", `expected key_type to be one of`},
+	} {
+		resource.UnitTest(t, resource.TestCase{
+			Providers: f.Providers(),
+			Steps: []resource.TestStep{
+				{
+					Config:      f.ProviderConfig() + testPKISecretBackendRole_config("pki", "web", tc.attributes),
+					ExpectError: regexp.MustCompile(tc.err),
+				},
+			},
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+)
+
+func pkiSecretBackendRootCertResource() *schema.Resource {
+	return &schema.Resource{
+		Create: pkiSecretBackendRootCertCreate,
+		Read:   pkiSecretBackendRootCertRead,
+		Delete: pkiSecretBackendRootCertDelete,
+
+		Schema: map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the PKI secrets engine is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"internal", "exported"}, false),
+				Description:  "Type of root to generate: exported returns the private key, internal keeps it in Vault.",
+			},
+
+			"common_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Common name of the root certificate.",
+			},
+
+			"alt_names": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "DNS names and email addresses to include as Subject Alternative Names.",
+			},
+
+			"ip_sans": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IP addresses to include as Subject Alternative Names.",
+			},
+
+			"exclude_cn_from_sans": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Leave the common name out of the Subject Alternative Names.",
+			},
+
+			"ttl": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: util.ValidateDuration,
+				Description:  "Validity of the root certificate, such as 87600h. The engine's maximum lease TTL applies when unset.",
+			},
+
+			"key_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "rsa",
+				ValidateFunc: validation.StringInSlice([]string{"rsa", "ec"}, false),
+				Description:  "Type of the key to generate, rsa or ec.",
+			},
+
+			"key_bits": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     2048,
+				Description: "Number of bits of the key to generate.",
+			},
+
+			"max_path_length": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     -1,
+				Description: "Maximum number of intermediate CAs below the root. There's no limit when set to -1.",
+			},
+
+			"permitted_dns_domains": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Domains that the CA, and the CAs below it, can issue certificates for.",
+			},
+
+			"ou": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Organizational unit of the root certificate.",
+			},
+
+			"organization": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Organization of the root certificate.",
+			},
+
+			"certificate": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded root certificate.",
+			},
+
+			"issuing_ca": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded certificate of the issuing CA, which is the root itself.",
+			},
+
+			"serial": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Serial number of the root certificate.",
+			},
+
+			"private_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "PEM-encoded private key of the root, when the type is exported.",
+			},
+
+			"private_key_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Type of the private key, when the type is exported.",
+			},
+		},
+	}
+}
+
+func pkiSecretBackendRootCertCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	path := backend + "/root/generate/" + d.Get("type").(string)
+
+	data := map[string]interface{}{
+		"common_name":           d.Get("common_name").(string),
+		"alt_names":             pkiStringList(d.Get("alt_names")),
+		"ip_sans":               pkiStringList(d.Get("ip_sans")),
+		"exclude_cn_from_sans":  d.Get("exclude_cn_from_sans").(bool),
+		"key_type":              d.Get("key_type").(string),
+		"key_bits":              d.Get("key_bits").(int),
+		"max_path_length":       d.Get("max_path_length").(int),
+		"permitted_dns_domains": pkiStringList(d.Get("permitted_dns_domains")),
+		"ou":                    d.Get("ou").(string),
+		"organization":          d.Get("organization").(string),
+		"format":                "pem",
+	}
+	if v, ok := d.GetOk("ttl"); ok {
+		data["ttl"] = v.(string)
+	}
+
+	log.Printf("[DEBUG] Generating root CA on PKI backend %q", backend)
+	secret, err := client.Logical().Write(path, data)
+	if err != nil {
+		return fmt.Errorf("error generating root CA on PKI backend %q: %s", backend, err)
+	}
+	if secret == nil {
+		return fmt.Errorf("no response generating root CA on PKI backend %q", backend)
+	}
+	log.Printf("[DEBUG] Generated root CA on PKI backend %q", backend)
+
+	d.SetId(path)
+	d.Set("certificate", secret.Data["certificate"])
+	d.Set("issuing_ca", secret.Data["issuing_ca"])
+	d.Set("serial", secret.Data["serial_number"])
+	d.Set("private_key", secret.Data["private_key"])
+	d.Set("private_key_type", secret.Data["private_key_type"])
+
+	return pkiSecretBackendRootCertRead(d, meta)
+}
+
+// pkiSecretBackendRootCertRead removes the root from the state when the
+// backend no longer has it as its CA, for example after it was deleted or
+// replaced outside of Terraform.
+func pkiSecretBackendRootCertRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	serial, err := pkiSecretBackendCASerial(client, backend)
+	if err != nil {
+		return err
+	}
+	if serial != d.Get("serial").(string) {
+		log.Printf("[WARN] CA of PKI backend %q is %q, not the root %q, removing root from state", backend, serial, d.Get("serial"))
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+func pkiSecretBackendRootCertDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+
+	log.Printf("[DEBUG] Deleting root CA from PKI backend %q", backend)
+	if _, err := client.Logical().Delete(backend + "/root"); err != nil {
+		return fmt.Errorf("error deleting root CA from PKI backend %q: %s", backend, err)
+	}
+	log.Printf("[DEBUG] Deleted root CA from PKI backend %q", backend)
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+)
+
+func pkiSecretBackendRootSignIntermediateResource() *schema.Resource {
+	return &schema.Resource{
+		Create: pkiSecretBackendRootSignIntermediateCreate,
+		Read:   pkiSecretBackendRootSignIntermediateRead,
+		Delete: pkiSecretBackendRootSignIntermediateDelete,
+
+		Schema: map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the PKI secrets engine of the root CA is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"csr": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "PEM-encoded certificate signing request of the intermediate CA.",
+			},
+
+			"common_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Common name of the intermediate CA.",
+			},
+
+			"use_csr_values": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Use the subject and SANs of the CSR rather than the arguments.",
+			},
+
+			"alt_names": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "DNS names and email addresses to include as Subject Alternative Names.",
+			},
+
+			"ip_sans": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IP addresses to include as Subject Alternative Names.",
+			},
+
+			"exclude_cn_from_sans": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Leave the common name out of the Subject Alternative Names.",
+			},
+
+			"ttl": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: util.ValidateDuration,
+				Description:  "Validity of the intermediate certificate, such as 43800h. The engine's maximum lease TTL applies when unset.",
+			},
+
+			"max_path_length": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     -1,
+				Description: "Maximum number of CAs below the intermediate CA. There's no limit when set to -1.",
+			},
+
+			"permitted_dns_domains": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Domains that the intermediate CA, and the CAs below it, can issue certificates for.",
+			},
+
+			"ou": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Organizational unit of the intermediate CA.",
+			},
+
+			"organization": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Organization of the intermediate CA.",
+			},
+
+			"certificate": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded intermediate certificate.",
+			},
+
+			"issuing_ca": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded certificate of the root CA that signed the intermediate.",
+			},
+
+			"serial": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Serial number of the intermediate certificate.",
+			},
+		},
+	}
+}
+
+func pkiSecretBackendRootSignIntermediateCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	path := backend + "/root/sign-intermediate"
+
+	data := map[string]interface{}{
+		"csr":                   d.Get("csr").(string),
+		"common_name":           d.Get("common_name").(string),
+		"use_csr_values":        d.Get("use_csr_values").(bool),
+		"alt_names":             pkiStringList(d.Get("alt_names")),
+		"ip_sans":               pkiStringList(d.Get("ip_sans")),
+		"exclude_cn_from_sans":  d.Get("exclude_cn_from_sans").(bool),
+		"max_path_length":       d.Get("max_path_length").(int),
+		"permitted_dns_domains": pkiStringList(d.Get("permitted_dns_domains")),
+		"ou":                    d.Get("ou").(string),
+		"organization":          d.Get("organization").(string),
+		"format":                "pem",
+	}
+	if v, ok := d.GetOk("ttl"); ok {
+		data["ttl"] = v.(string)
+	}
+
+	log.Printf("[DEBUG] Signing intermediate CA with PKI backend %q", backend)
+	secret, err := client.Logical().Write(path, data)
+	if err != nil {
+		return fmt.Errorf("error signing intermediate CA with PKI backend %q: %s", backend, err)
+	}
+	if secret == nil {
+		return fmt.Errorf("no response signing intermediate CA with PKI backend %q", backend)
+	}
+	log.Printf("[DEBUG] Signed intermediate CA with PKI backend %q", backend)
+
+	serial, _ := secret.Data["serial_number"].(string)
+	d.SetId(path + "/" + serial)
+	d.Set("certificate", secret.Data["certificate"])
+	d.Set("issuing_ca", secret.Data["issuing_ca"])
+	d.Set("serial", serial)
+
+	return pkiSecretBackendRootSignIntermediateRead(d, meta)
+}
+
+// pkiSecretBackendRootSignIntermediateRead removes the intermediate
+// certificate from the state when the root CA that signed it is no longer
+// the CA of the backend, so that the next apply signs the request again.
+func pkiSecretBackendRootSignIntermediateRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	issuingCA, err := pkiParseCertificate(d.Get("issuing_ca").(string))
+	if err != nil {
+		return fmt.Errorf("error parsing issuing_ca: %s", err)
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	serial, err := pkiSecretBackendCASerial(client, backend)
+	if err != nil {
+		return err
+	}
+	if serial != pkiCertificateSerial(issuingCA) {
+		log.Printf("[WARN] Root CA that signed %q is no longer the CA of PKI backend %q, removing from state", d.Id(), backend)
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+// pkiSecretBackendRootSignIntermediateDelete only removes the certificate
+// from the state. It stays valid until it expires.
+func pkiSecretBackendRootSignIntermediateDelete(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
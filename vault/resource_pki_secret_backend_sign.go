@@ -0,0 +1,192 @@
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+)
+
+// pkiSecretBackendSignResource signs CSRs with a role. Once signed, the
+// certificate is read, renewed and revoked like an issued one.
+func pkiSecretBackendSignResource() *schema.Resource {
+	return &schema.Resource{
+		Create: pkiSecretBackendSignCreate,
+		Read:   pkiSecretBackendCertRead,
+		Update: pkiSecretBackendCertUpdate,
+		Delete: pkiSecretBackendCertDelete,
+
+		Schema: map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the PKI secrets engine is mounted.",
+				// standardise on no beginning or trailing slashes
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the role to sign the certificate with.",
+			},
+
+			"csr": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validatePKICSR,
+				Description:  "PEM-encoded certificate signing request.",
+			},
+
+			"common_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Common name of the certificate, when the role doesn't use the one of the CSR.",
+			},
+
+			"alt_names": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "DNS names and email addresses to include as Subject Alternative Names, when the role doesn't use those of the CSR.",
+			},
+
+			"ip_sans": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IP addresses to include as Subject Alternative Names, when the role doesn't use those of the CSR.",
+			},
+
+			"exclude_cn_from_sans": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Leave the common name out of the Subject Alternative Names.",
+			},
+
+			"ttl": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: util.ValidateDuration,
+				Description:  "Validity of the certificate, such as 720h. The role's TTL applies when unset.",
+			},
+
+			"min_seconds_remaining": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     604800,
+				Description: "Sign the CSR again when the certificate expires in fewer seconds than this.",
+			},
+
+			"revoke": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Revoke the certificate when it's destroyed, including when the CSR is signed again.",
+			},
+
+			"certificate": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded certificate.",
+			},
+
+			"issuing_ca": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM-encoded certificate of the issuing CA.",
+			},
+
+			"ca_chain": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "PEM-encoded certificates of the CA chain.",
+			},
+
+			"serial": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Serial number of the certificate.",
+			},
+
+			"expiration": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Expiration of the certificate, as a Unix timestamp.",
+			},
+
+			"renew_pending": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the certificate expires within min_seconds_remaining, and the CSR will be signed again.",
+			},
+		},
+	}
+}
+
+func validatePKICSR(v interface{}, k string) ([]string, []error) {
+	if _, err := pkiParseCSR(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+func pkiSecretBackendSignCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := strings.Trim(d.Get("backend").(string), "/")
+	name := d.Get("name").(string)
+	path := backend + "/sign/" + name
+
+	// The CSR isn't validated at plan time when it's computed, such as
+	// when another resource generates it.
+	csr := d.Get("csr").(string)
+	if _, err := pkiParseCSR(csr); err != nil {
+		return fmt.Errorf("error parsing csr: %s", err)
+	}
+
+	data := map[string]interface{}{
+		"csr":                  csr,
+		"common_name":          d.Get("common_name").(string),
+		"alt_names":            pkiStringList(d.Get("alt_names")),
+		"ip_sans":              pkiStringList(d.Get("ip_sans")),
+		"exclude_cn_from_sans": d.Get("exclude_cn_from_sans").(bool),
+		"format":               "pem",
+	}
+	if v, ok := d.GetOk("ttl"); ok {
+		data["ttl"] = v.(string)
+	}
+
+	log.Printf("[DEBUG] Signing certificate with role %q on PKI backend %q", name, backend)
+	secret, err := client.Logical().Write(path, data)
+	if err != nil {
+		return fmt.Errorf("error signing certificate with role %q on PKI backend %q: %s", name, backend, err)
+	}
+	if secret == nil {
+		return fmt.Errorf("no response signing certificate with role %q on PKI backend %q", name, backend)
+	}
+	log.Printf("[DEBUG] Signed certificate with role %q on PKI backend %q", name, backend)
+
+	if err := pkiSecretBackendCertSetIssued(d, path, secret.Data); err != nil {
+		return err
+	}
+
+	return pkiSecretBackendCertRead(d, meta)
+}
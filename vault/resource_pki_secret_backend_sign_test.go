@@ -0,0 +1,256 @@
+package vault
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/vault/helper/certutil"
+)
+
+func TestAccPKISecretBackendSign_basic(t *testing.T) {
+	backend := acctest.RandomWithPrefix("tf-test-pki")
+	key, csr := testPKISecretBackendSign_csr(t, "www.example.com", "api.example.com")
+	resource.Test(t, resource.TestCase{
+		Providers: testProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testPKISecretBackend_mountConfig("test", backend) +
+					testPKISecretBackendSign_config("${vault_mount.test.path}", csr, `
+  ttl                   = "1h"
+  min_seconds_remaining = 60
+  revoke                = true
+`),
+				Check: testPKISecretBackendSign_check(key, "www.example.com", time.Hour),
+			},
+		},
+	})
+}
+
+// testPKISecretBackendSign_csr generates a key, and a CSR for it with the
+// common name and DNS names.
+func testPKISecretBackendSign_csr(t *testing.T, commonName string, dnsNames ...string) (crypto.Signer, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: commonName},
+		DNSNames: dnsNames,
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+}
+
+func testPKISecretBackendSign_config(backend, csr, extra string) string {
+	return testPKISecretBackendCert_roleConfig(backend, "") + fmt.Sprintf(`
+resource "vault_pki_secret_backend_sign" "test" {
+  backend = "${vault_pki_secret_backend_role.test.backend}"
+  name    = "${vault_pki_secret_backend_role.test.name}"
+  csr     = <<EOT
+%sEOT
+%s}
+`, csr, extra)
+}
+
+// testPKISecretBackendSign_check checks that the certificate in the state
+// was signed by the root for the key, with the common name, for about ttl.
+func testPKISecretBackendSign_check(key crypto.Signer, commonName string, ttl time.Duration) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		resources := s.RootModule().Resources
+		attrs := resources["vault_pki_secret_backend_sign.test"].Primary.Attributes
+		cert, err := pkiParseCertificate(attrs["certificate"])
+		if err != nil {
+			return err
+		}
+		root, err := pkiParseCertificate(resources["vault_pki_secret_backend_root_cert.test"].Primary.Attributes["certificate"])
+		if err != nil {
+			return err
+		}
+
+		if err := cert.CheckSignatureFrom(root); err != nil {
+			return fmt.Errorf("certificate isn't signed by the root: %s", err)
+		}
+		if equal, err := certutil.ComparePublicKeys(cert.PublicKey, key.Public()); err != nil || !equal {
+			return fmt.Errorf("certificate isn't for the key of the CSR")
+		}
+		if cert.Subject.CommonName != commonName {
+			return fmt.Errorf("common name is %q; want %q", cert.Subject.CommonName, commonName)
+		}
+		if validity := cert.NotAfter.Sub(cert.NotBefore); validity < ttl || validity > ttl+time.Minute {
+			return fmt.Errorf("certificate is valid for %s; want %s", validity, ttl)
+		}
+		if serial := pkiCertificateSerial(cert); attrs["serial"] != serial {
+			return fmt.Errorf("serial is %q; want %q", attrs["serial"], serial)
+		}
+		if attrs["issuing_ca"] != resources["vault_pki_secret_backend_root_cert.test"].Primary.Attributes["certificate"] {
+			return fmt.Errorf("issuing_ca isn't the root certificate")
+		}
+		return nil
+	}
+}
+
+func TestPKISecretBackendSign_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki", "pki", nil)
+	key, csr := testPKISecretBackendSign_csr(t, "www.example.com", "api.example.com")
+
+	var first, second string
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testPKISecretBackendSign_config("pki", csr, `
+  ttl                   = "1h"
+  min_seconds_remaining = 60
+  revoke                = true
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testPKISecretBackendSign_check(key, "www.example.com", time.Hour),
+					resource.TestMatchResourceAttr("vault_pki_secret_backend_sign.test", "id", regexp.MustCompile(`^pki/sign/web/[0-9a-f:]+$`)),
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_sign.test", "renew_pending", "false"),
+					testPKISecretBackendCert_serial("vault_pki_secret_backend_sign.test", &first),
+				),
+			},
+			{
+				// Within the renewal window, the certificate is flagged on
+				// refresh and planned to be signed again.
+				Config: f.ProviderConfig() + testPKISecretBackendSign_config("pki", csr, `
+  ttl                   = "1h"
+  min_seconds_remaining = 7200
+  revoke                = true
+`),
+				ExpectNonEmptyPlan: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_sign.test", "renew_pending", "true"),
+					resource.TestCheckResourceAttrPtr("vault_pki_secret_backend_sign.test", "serial", &first),
+				),
+			},
+			{
+				// The flagged certificate is revoked and the same CSR
+				// signed again.
+				Config: f.ProviderConfig() + testPKISecretBackendSign_config("pki", csr, `
+  ttl                   = "1h"
+  min_seconds_remaining = 60
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testPKISecretBackendSign_check(key, "www.example.com", time.Hour),
+					resource.TestCheckResourceAttr("vault_pki_secret_backend_sign.test", "renew_pending", "false"),
+					testPKISecretBackendCert_serial("vault_pki_secret_backend_sign.test", &second),
+					func(*terraform.State) error {
+						if second == first {
+							return fmt.Errorf("certificate not signed again")
+						}
+						return nil
+					},
+					testPKISecretBackendCert_fakeVaultRevoked(f, &first, true),
+				),
+			},
+		},
+		CheckDestroy: testPKISecretBackendCert_fakeVaultRevoked(f, &second, false),
+	})
+}
+
+func TestPKISecretBackendSign_fakeVaultRoleValues(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki", "pki", nil)
+	key, csr := testPKISecretBackendSign_csr(t, "ignored.example.org")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				// The role takes the common name from the arguments rather
+				// than from the CSR.
+				Config: f.ProviderConfig() + testPKISecretBackendCert_roleConfig("pki", `
+  use_csr_common_name = false
+  use_csr_sans        = false
+`) + fmt.Sprintf(`
+resource "vault_pki_secret_backend_sign" "test" {
+  backend     = "${vault_pki_secret_backend_role.test.backend}"
+  name        = "${vault_pki_secret_backend_role.test.name}"
+  common_name           = "www.example.com"
+  ttl                   = "1h"
+  min_seconds_remaining = 60
+  csr                   = <<EOT
+%sEOT
+}
+`, csr),
+				Check: testPKISecretBackendSign_check(key, "www.example.com", time.Hour),
+			},
+		},
+	})
+}
+
+func TestPKISecretBackendSign_fakeVaultInvalid(t *testing.T) {
+	f := newFakeVault(t)
+	f.Mount("pki", "pki", nil)
+	_, csr := testPKISecretBackendSign_csr(t, "www.example.com")
+
+	// A CSR with a corrupted signature.
+	block, _ := pem.Decode([]byte(csr))
+	block.Bytes[len(block.Bytes)-1] ^= 0xff
+	tampered := string(pem.EncodeToMemory(block))
+
+	for _, tc := range []struct {
+		config string
+		err    string
+	}{
+		{
+			testPKISecretBackendSign_config("pki", "not a CSR\n", ""),
+			`csr: no PEM-encoded certificate request found`,
+		},
+		{
+			testPKISecretBackendSign_config("pki", tampered, ""),
+			`csr: invalid signature of certificate request`,
+		},
+		{
+			testPKISecretBackendSign_config("pki", strings.Replace(csr, "CERTIFICATE REQUEST", "CERTIFICATE", -1), ""),
+			`csr: no PEM-encoded certificate request found`,
+		},
+		{
+			// Vault rejects a key of another type than the role's.
+			testPKISecretBackendCert_roleConfig("pki", "") + `
+resource "vault_pki_secret_backend_role" "rsa" {
+  backend            = "${vault_pki_secret_backend_root_cert.test.backend}"
+  name               = "rsa"
+  allowed_domains    = ["example.com"]
+  allow_bare_domains = true
+}
+
+resource "vault_pki_secret_backend_sign" "test" {
+  backend = "${vault_pki_secret_backend_role.rsa.backend}"
+  name    = "${vault_pki_secret_backend_role.rsa.name}"
+  csr     = <<EOT
+` + csr + `EOT
+}
+`,
+			`(?s)error signing certificate with role .*rsa.* on PKI backend .*role requires keys of type rsa`,
+		},
+	} {
+		resource.UnitTest(t, resource.TestCase{
+			Providers: f.Providers(),
+			Steps: []resource.TestStep{
+				{
+					Config:      f.ProviderConfig() + tc.config,
+					ExpectError: regexp.MustCompile(tc.err),
+				},
+			},
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"log"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
 )
 
 func policyResource() *schema.Resource {
@@ -36,13 +35,16 @@ func policyResource() *schema.Resource {
 }
 
 func policyWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	name := d.Get("name").(string)
 	policy := d.Get("policy").(string)
 
 	log.Printf("[DEBUG] Writing policy %s to Vault", name)
-	err := client.Sys().PutPolicy(name, policy)
+	err = client.Sys().PutPolicy(name, policy)
 
 	if err != nil {
 		return fmt.Errorf("error writing to Vault: %s", err)
@@ -54,13 +56,16 @@ func policyWrite(d *schema.ResourceData, meta interface{}) error {
 }
 
 func policyDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	name := d.Id()
 
 	log.Printf("[DEBUG] Deleting policy %s from Vault", name)
 
-	err := client.Sys().DeletePolicy(name)
+	err = client.Sys().DeletePolicy(name)
 	if err != nil {
 		return fmt.Errorf("error deleting from Vault: %s", err)
 	}
@@ -69,7 +74,10 @@ func policyDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func policyRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	name := d.Id()
 
@@ -78,6 +86,11 @@ func policyRead(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return fmt.Errorf("error reading from Vault: %s", err)
 	}
+	if policy == "" {
+		log.Printf("[WARN] Policy %q not found, removing from state", name)
+		d.SetId("")
+		return nil
+	}
 
 	d.Set("policy", policy)
 	d.Set("name", name)
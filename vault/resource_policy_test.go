@@ -2,12 +2,12 @@ package vault
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
-	"github.com/hashicorp/vault/api"
 )
 
 func TestResourcePolicy(t *testing.T) {
@@ -63,7 +63,7 @@ func testResourcePolicy_initialCheck(expectedName string) resource.TestCheckFunc
 			return fmt.Errorf("unexpected policy name %q, expected %q", name, expectedName)
 		}
 
-		client := testProvider.Meta().(*api.Client)
+		client := testProvider.Meta().(*ProviderMeta).GetClient()
 		policy, err := client.Sys().GetPolicy(name)
 		if err != nil {
 			return fmt.Errorf("error reading back policy: %s", err)
@@ -96,7 +96,7 @@ func testResourcePolicy_updateCheck(s *terraform.State) error {
 
 	name := instanceState.ID
 
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 
 	if name != instanceState.Attributes["name"] {
 		return fmt.Errorf("id doesn't match name")
@@ -117,3 +117,88 @@ func testResourcePolicy_updateCheck(s *terraform.State) error {
 
 	return nil
 }
+
+func TestResourcePolicy_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		CheckDestroy: func(s *terraform.State) error {
+			if _, ok := f.Policy("dev-team"); ok {
+				return fmt.Errorf("policy still exists")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourcePolicy_updateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_policy.test", "name", "dev-team"),
+					testResourcePolicy_fakeVaultCheck(f, "dev-team", "write"),
+				),
+			},
+			{
+				// A policy removed outside of Terraform is created again.
+				PreConfig: func() {
+					f.Write("sys/policy/dev-team", map[string]interface{}{"rules": "changed"})
+				},
+				Config: f.ProviderConfig() + testResourcePolicy_updateConfig,
+				Check:  testResourcePolicy_fakeVaultCheck(f, "dev-team", "write"),
+			},
+			{
+				ResourceName:      "vault_policy.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				Config:            f.ProviderConfig() + testResourcePolicy_updateConfig,
+			},
+		},
+	})
+}
+
+func TestResourcePolicy_fakeVaultRemoved(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourcePolicy_updateConfig,
+			},
+			{
+				PreConfig: func() {
+					f.Delete("sys/policy/dev-team")
+				},
+				Config: f.ProviderConfig() + testResourcePolicy_updateConfig,
+				Check:  testResourcePolicy_fakeVaultCheck(f, "dev-team", "write"),
+			},
+		},
+	})
+}
+
+func TestResourcePolicy_fakeVaultPermissionDenied(t *testing.T) {
+	f := newFakeVault(t)
+	f.Write("sys/policy/dev-team", map[string]interface{}{"rules": "path \"secret/*\" {}"})
+	f.InjectFault(fakeVaultFault{Method: "GET", Path: "sys/policies/acl/dev-team", Status: 403})
+
+	d := policyResource().TestResourceData()
+	d.SetId("dev-team")
+
+	// A 403 must fail the refresh rather than drop the policy from state.
+	if err := policyRead(d, f.Meta()); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected a permission denied error, got %v", err)
+	}
+	if d.Id() != "dev-team" {
+		t.Fatalf("expected the policy to stay in state")
+	}
+}
+
+func testResourcePolicy_fakeVaultCheck(f *fakeVault, name, capability string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		policy, ok := f.Policy(name)
+		if !ok {
+			return fmt.Errorf("policy %q not found", name)
+		}
+		if want := fmt.Sprintf("path \"secret/*\" {\n\tpolicy = %q\n}\n", capability); policy != want {
+			return fmt.Errorf("policy data is %q; want %q", policy, want)
+		}
+		return nil
+	}
+}
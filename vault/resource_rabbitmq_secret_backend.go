@@ -82,7 +82,10 @@ func rabbitmqSecretBackendResource() *schema.Resource {
 }
 
 func rabbitmqSecretBackendCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Get("path").(string)
 	description := d.Get("description").(string)
@@ -95,7 +98,7 @@ func rabbitmqSecretBackendCreate(d *schema.ResourceData, meta interface{}) error
 
 	d.Partial(true)
 	log.Printf("[DEBUG] Mounting Rabbitmq backend at %q", path)
-	err := client.Sys().Mount(path, &api.MountInput{
+	err = client.Sys().Mount(path, &api.MountInput{
 		Type:        "rabbitmq",
 		Description: description,
 		Config: api.MountConfigInput{
@@ -135,7 +138,10 @@ func rabbitmqSecretBackendCreate(d *schema.ResourceData, meta interface{}) error
 }
 
 func rabbitmqSecretBackendRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -164,7 +170,10 @@ func rabbitmqSecretBackendRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func rabbitmqSecretBackendUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	d.Partial(true)
@@ -205,11 +214,14 @@ func rabbitmqSecretBackendUpdate(d *schema.ResourceData, meta interface{}) error
 }
 
 func rabbitmqSecretBackendDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Unmounting RabbitMQ backend %q", path)
-	err := client.Sys().Unmount(path)
+	err = client.Sys().Unmount(path)
 	if err != nil {
 		return fmt.Errorf("error unmounting RabbitMQ backend from %q: %s", path, err)
 	}
@@ -218,7 +230,10 @@ func rabbitmqSecretBackendDelete(d *schema.ResourceData, meta interface{}) error
 }
 
 func rabbitmqSecretBackendExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if RabbitMQ backend exists at %q", path)
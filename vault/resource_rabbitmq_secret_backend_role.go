@@ -6,7 +6,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 func rabbitmqSecretBackendRoleResource() *schema.Resource {
@@ -50,7 +50,10 @@ func rabbitmqSecretBackendRoleResource() *schema.Resource {
 }
 
 func rabbitmqSecretBackendRoleWrite(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Get("backend").(string)
 	name := d.Get("name").(string)
@@ -62,7 +65,7 @@ func rabbitmqSecretBackendRoleWrite(d *schema.ResourceData, meta interface{}) er
 		"vhosts": vhosts,
 	}
 	log.Printf("[DEBUG] Creating role %q on Rabbitmq backend %q", name, backend)
-	_, err := client.Logical().Write(backend+"/roles/"+name, data)
+	_, err = client.Logical().Write(backend+"/roles/"+name, data)
 	if err != nil {
 		return fmt.Errorf("error creating role %q for backend %q: %s", name, backend, err)
 	}
@@ -77,7 +80,10 @@ func rabbitmqSecretBackendRoleWrite(d *schema.ResourceData, meta interface{}) er
 }
 
 func rabbitmqSecretBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	pathPieces := strings.Split(path, "/")
@@ -87,7 +93,7 @@ func rabbitmqSecretBackendRoleRead(d *schema.ResourceData, meta interface{}) err
 
 	log.Printf("[DEBUG] Reading role from %q", path)
 	secret, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("error reading role %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read role from %q", path)
@@ -104,11 +110,14 @@ func rabbitmqSecretBackendRoleRead(d *schema.ResourceData, meta interface{}) err
 }
 
 func rabbitmqSecretBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Deleting role %q", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("error deleting role %q: %s", path, err)
 	}
@@ -117,12 +126,15 @@ func rabbitmqSecretBackendRoleDelete(d *schema.ResourceData, meta interface{}) e
 }
 
 func rabbitmqSecretBackendRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if %q exists", path)
 	secret, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if %q exists: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if %q exists", path)
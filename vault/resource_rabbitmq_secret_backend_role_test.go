@@ -7,7 +7,6 @@ import (
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/vault/api"
 )
 
 const testAccRabbitmqSecretBackendRoleTags_basic = `management`
@@ -105,7 +104,7 @@ func TestAccRabbitmqSecretBackendRole_nested(t *testing.T) {
 }
 
 func testAccRabbitmqSecretBackendRoleCheckDestroy(s *terraform.State) error {
-	client := testProvider.Meta().(*api.Client)
+	client := testProvider.Meta().(*ProviderMeta).GetClient()
 
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "vault_rabbitmq_secret_backend_role" {
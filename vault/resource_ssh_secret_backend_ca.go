@@ -3,7 +3,7 @@ package vault
 import (
 	"fmt"
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 	"log"
 	"strings"
 )
@@ -56,7 +56,10 @@ func sshSecretBackendCAResource() *schema.Resource {
 }
 
 func sshSecretBackendCACreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	backend := d.Get("backend").(string)
 
 	data := make(map[string]interface{})
@@ -71,7 +74,7 @@ func sshSecretBackendCACreate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	log.Printf("[DEBUG] Writing CA information on SSH backend %q", backend)
-	_, err := client.Logical().Write(backend+"/config/ca", data)
+	_, err = client.Logical().Write(backend+"/config/ca", data)
 	if err != nil {
 		return fmt.Errorf("Error writing CA information for backend %q: %s", backend, err)
 	}
@@ -82,13 +85,16 @@ func sshSecretBackendCACreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func sshSecretBackendCARead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Id()
 
 	log.Printf("[DEBUG] Reading CA information from SSH backend %q", backend)
 	secret, err := client.Logical().Read(backend + "/config/ca")
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("Error reading CA information from SSH backend %q: %s", backend, err)
 	}
 	log.Printf("[DEBUG] Read CA information from SSH backend %q", backend)
@@ -107,11 +113,14 @@ func sshSecretBackendCARead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func sshSecretBackendCADelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	backend := d.Id()
 	log.Printf("[DEBUG] Deleting CA configuration for SSH backend %q", backend)
-	_, err := client.Logical().Delete(backend + "/config/ca")
+	_, err = client.Logical().Delete(backend + "/config/ca")
 	if err != nil {
 		return fmt.Errorf("Error deleting CA configuration for SSH backend %q: %s", backend, err)
 	}
@@ -121,12 +130,15 @@ func sshSecretBackendCADelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func sshSecretBackendCAExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	backend := d.Id()
 	log.Printf("[DEBUG] Checking if CA information exists for backend %q ", backend)
 	secret, err := client.Logical().Read(backend + "/config/ca")
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("Error checking if CA information exists for backend %q: %s", backend, err)
 	}
 	log.Printf("[DEBUG] Checked if CA information exists for backend %q", backend)
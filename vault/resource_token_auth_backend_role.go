@@ -7,7 +7,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
 )
 
 var (
@@ -94,7 +94,10 @@ func tokenAuthBackendRoleResource() *schema.Resource {
 }
 
 func tokenAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	role := d.Get("role_name").(string)
 
@@ -133,7 +136,7 @@ func tokenAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error
 
 	d.SetId(path)
 
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		d.SetId("")
 		return fmt.Errorf("Error writing Token auth backend role %q: %s", path, err)
@@ -144,7 +147,10 @@ func tokenAuthBackendRoleCreate(d *schema.ResourceData, meta interface{}) error
 }
 
 func tokenAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	roleName, err := tokenAuthBackendRoleNameFromPath(path)
@@ -154,7 +160,7 @@ func tokenAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
 
 	log.Printf("[DEBUG] Reading Token auth backend role %q", path)
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return fmt.Errorf("Error reading Token auth backend role %q: %s", path, err)
 	}
 	log.Printf("[DEBUG] Read Token auth backend role %q", path)
@@ -191,7 +197,10 @@ func tokenAuthBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func tokenAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Updating Token auth backend role %q", path)
@@ -225,7 +234,7 @@ func tokenAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error
 	data["ttl"] = d.Get("ttl").(string)
 	data["max_ttl"] = d.Get("max_ttl").(string)
 
-	_, err := client.Logical().Write(path, data)
+	_, err = client.Logical().Write(path, data)
 	if err != nil {
 		return fmt.Errorf("error updating Token auth backend role %q: %s", path, err)
 	}
@@ -235,11 +244,14 @@ func tokenAuthBackendRoleUpdate(d *schema.ResourceData, meta interface{}) error
 }
 
 func tokenAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 	path := d.Id()
 
 	log.Printf("[DEBUG] Deleting Token auth backend role %q", path)
-	_, err := client.Logical().Delete(path)
+	_, err = client.Logical().Delete(path)
 	if err != nil {
 		return fmt.Errorf("error deleting Token auth backend role %q", path)
 	}
@@ -249,13 +261,16 @@ func tokenAuthBackendRoleDelete(d *schema.ResourceData, meta interface{}) error
 }
 
 func tokenAuthBackendRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*api.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return false, err
+	}
 
 	path := d.Id()
 	log.Printf("[DEBUG] Checking if Token auth backend role %q exists", path)
 
 	resp, err := client.Logical().Read(path)
-	if err != nil {
+	if err != nil && !util.IsNotFound(err) {
 		return true, fmt.Errorf("error checking if Token auth backend role %q exists: %s", path, err)
 	}
 	log.Printf("[DEBUG] Checked if Token auth backend role %q exists", path)
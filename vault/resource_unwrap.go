@@ -0,0 +1,220 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/vault/api"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+)
+
+func unwrapResource() *schema.Resource {
+	return &schema.Resource{
+		Create: unwrapResourceCreate,
+		Read:   unwrapResourceRead,
+		Delete: unwrapResourceDelete,
+
+		Schema: map[string]*schema.Schema{
+			"token": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Sensitive:   true,
+				Description: "Response-wrapping token to unwrap.",
+			},
+
+			"expected_creation_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Path that the wrapped response must have been created by. The token isn't unwrapped otherwise.",
+			},
+
+			"creation_path": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Path of the request whose response was wrapped.",
+			},
+
+			"creation_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time at which the response was wrapped.",
+			},
+
+			"creation_ttl": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of seconds for which the token was valid when it was created.",
+			},
+
+			"data_json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "JSON-encoded data of the unwrapped response.",
+			},
+
+			"data": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Map of strings in the data of the unwrapped response.",
+			},
+
+			"lease_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Lease identifier of the unwrapped response.",
+			},
+
+			"lease_duration": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Lease duration of the unwrapped response, in seconds.",
+			},
+
+			"lease_renewable": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the lease of the unwrapped response can be renewed.",
+			},
+
+			"auth_client_token": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Token in the auth section of the unwrapped response.",
+			},
+
+			"auth_accessor": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Accessor of the token in the unwrapped response.",
+			},
+
+			"auth_policies": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Policies of the token in the unwrapped response.",
+			},
+
+			"auth_metadata": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Metadata of the token in the unwrapped response.",
+			},
+
+			"auth_lease_duration": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Lease duration of the token in the unwrapped response, in seconds.",
+			},
+
+			"auth_renewable": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the token in the unwrapped response can be renewed.",
+			},
+		},
+	}
+}
+
+func unwrapResourceCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	token := d.Get("token").(string)
+
+	// Unwrapping uses the token up, so the wrapped response is checked
+	// first.
+	log.Printf("[DEBUG] Looking up wrapping token")
+	lookup, err := client.Logical().Write("sys/wrapping/lookup", map[string]interface{}{
+		"token": token,
+	})
+	if err != nil {
+		if util.StatusCode(err) == http.StatusBadRequest {
+			// Such as when the token was already unwrapped.
+			return fmt.Errorf("error looking up wrapping token, which can only be unwrapped once: %s", err)
+		}
+		return fmt.Errorf("error looking up wrapping token: %s", err)
+	}
+	if lookup == nil {
+		return fmt.Errorf("no response looking up wrapping token")
+	}
+
+	creationPath, _ := lookup.Data["creation_path"].(string)
+	if expected, ok := d.GetOk("expected_creation_path"); ok {
+		if strings.Trim(creationPath, "/") != strings.Trim(expected.(string), "/") {
+			return fmt.Errorf("wrapping token was created by %q, not by the expected %q, so it was not unwrapped", creationPath, expected)
+		}
+	}
+	var creationTTL int64
+	if v, ok := lookup.Data["creation_ttl"].(json.Number); ok {
+		creationTTL, err = v.Int64()
+		if err != nil {
+			return fmt.Errorf("unexpected creation_ttl %q: %s", v, err)
+		}
+	}
+
+	log.Printf("[DEBUG] Unwrapping response from %q", creationPath)
+	secret, err := client.Logical().Unwrap(token)
+	if err != nil {
+		return fmt.Errorf("error unwrapping response from %q: %s", creationPath, err)
+	}
+	if secret == nil {
+		return fmt.Errorf("no response unwrapping response from %q", creationPath)
+	}
+	log.Printf("[DEBUG] Unwrapped response from %q", creationPath)
+
+	d.SetId(secret.RequestID)
+	d.Set("creation_path", creationPath)
+	d.Set("creation_time", lookup.Data["creation_time"])
+	d.Set("creation_ttl", int(creationTTL))
+
+	data := secret.Data
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON for the response from %q: %s", creationPath, err)
+	}
+	d.Set("data_json", string(jsonData))
+	d.Set("data", serializeDataMapToString(data))
+	d.Set("lease_id", secret.LeaseID)
+	d.Set("lease_duration", secret.LeaseDuration)
+	d.Set("lease_renewable", secret.Renewable)
+
+	auth := secret.Auth
+	if auth == nil {
+		auth = &api.SecretAuth{}
+	}
+	d.Set("auth_client_token", auth.ClientToken)
+	d.Set("auth_accessor", auth.Accessor)
+	d.Set("auth_policies", auth.Policies)
+	d.Set("auth_metadata", auth.Metadata)
+	d.Set("auth_lease_duration", auth.LeaseDuration)
+	d.Set("auth_renewable", auth.Renewable)
+
+	return nil
+}
+
+// unwrapResourceRead does nothing: the token was used up when it was
+// unwrapped, so the response only lives in the state.
+func unwrapResourceRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+// unwrapResourceDelete does nothing: there is nothing left in Vault to
+// delete once the token is unwrapped.
+func unwrapResourceDelete(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
@@ -0,0 +1,148 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// There's no acceptance test, since a wrapping token can only be unwrapped
+// once and has to be created for each run.
+
+func testResourceUnwrap_config(token, expectedCreationPath string) string {
+	return fmt.Sprintf(`
+resource "vault_unwrap" "test" {
+  token                  = %q
+  expected_creation_path = %q
+}
+`, token, expectedCreationPath)
+}
+
+// testResourceUnwrap_unwrapCount checks how many times a token was
+// unwrapped.
+func testResourceUnwrap_unwrapCount(f *fakeVault, want int) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if got := f.RequestCount("PUT", "sys/wrapping/unwrap"); got != want {
+			return fmt.Errorf("tokens were unwrapped %d times; want %d", got, want)
+		}
+		return nil
+	}
+}
+
+func TestResourceUnwrap_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	token := f.Wrap("secret/data/app", 300, map[string]interface{}{
+		"data":           map[string]interface{}{"zip": "zap", "count": 2},
+		"lease_duration": 3600,
+	})
+	replacement := f.Wrap("secret/data/app", 300, map[string]interface{}{
+		"data": map[string]interface{}{"zip": "zoop"},
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + testResourceUnwrap_config(token, "/secret/data/app"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_unwrap.test", "creation_path", "secret/data/app"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "creation_ttl", "300"),
+					resource.TestCheckResourceAttrSet("vault_unwrap.test", "creation_time"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "data.%", "2"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "data.zip", "zap"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "data.count", "2"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "data_json", `{"count":2,"zip":"zap"}`),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "lease_duration", "3600"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "auth_client_token", ""),
+					testResourceUnwrap_unwrapCount(f, 1),
+				),
+			},
+			{
+				// The response is kept in the state, so planning again
+				// doesn't try to unwrap the used-up token.
+				Config: f.ProviderConfig() + testResourceUnwrap_config(token, "/secret/data/app"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_unwrap.test", "data.zip", "zap"),
+					testResourceUnwrap_unwrapCount(f, 1),
+				),
+			},
+			{
+				Config: f.ProviderConfig() + testResourceUnwrap_config(replacement, "/secret/data/app"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_unwrap.test", "data.%", "1"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "data.zip", "zoop"),
+					testResourceUnwrap_unwrapCount(f, 2),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceUnwrap_fakeVaultAuth(t *testing.T) {
+	f := newFakeVault(t)
+	token := f.Wrap("auth/approle/login", 60, map[string]interface{}{
+		"auth": map[string]interface{}{
+			"client_token":   "s.child",
+			"accessor":       "child-accessor",
+			"policies":       []string{"default", "app"},
+			"metadata":       map[string]string{"role_name": "app"},
+			"lease_duration": 1200,
+			"renewable":      true,
+		},
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				// No expected_creation_path.
+				Config: f.ProviderConfig() + testResourceUnwrap_config(token, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vault_unwrap.test", "creation_path", "auth/approle/login"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "data.%", "0"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "auth_client_token", "s.child"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "auth_accessor", "child-accessor"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "auth_policies.#", "2"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "auth_policies.1", "app"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "auth_metadata.role_name", "app"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "auth_lease_duration", "1200"),
+					resource.TestCheckResourceAttr("vault_unwrap.test", "auth_renewable", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceUnwrap_fakeVaultUnexpectedCreationPath(t *testing.T) {
+	f := newFakeVault(t)
+	token := f.Wrap("secret/data/other", 300, map[string]interface{}{
+		"data": map[string]interface{}{"zip": "zap"},
+	})
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config:      f.ProviderConfig() + testResourceUnwrap_config(token, "secret/data/app"),
+				ExpectError: regexp.MustCompile(`wrapping token was created by .*secret/data/other.*, not by the expected .*secret/data/app.*, so it was not unwrapped`),
+			},
+		},
+		// The token is left for whoever it was meant for.
+		CheckDestroy: testResourceUnwrap_unwrapCount(f, 0),
+	})
+}
+
+func TestResourceUnwrap_fakeVaultInvalidToken(t *testing.T) {
+	f := newFakeVault(t)
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config:      f.ProviderConfig() + testResourceUnwrap_config("not-a-token", ""),
+				ExpectError: regexp.MustCompile(`(?s)error looking up wrapping token, which can only be unwrapped once: .*wrapping token is not valid or does not exist`),
+			},
+		},
+	})
+}
@@ -0,0 +1,154 @@
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/consts"
+)
+
+// serverVersionRequirement is the oldest Vault release that supports a
+// resource or one of its attributes.
+type serverVersionRequirement struct {
+	// Attribute is empty when the requirement applies to the whole
+	// resource.
+	Attribute string
+	Version   *version.Version
+}
+
+// serverVersionRequirements lists, by resource type, what the connected
+// server must support. They are checked during plan, and only when the
+// server version is known.
+var serverVersionRequirements = map[string][]serverVersionRequirement{
+	"vault_auth_backend": {
+		{Attribute: "listing_visibility", Version: version.Must(version.NewVersion("0.10.0"))},
+	},
+	"vault_gcp_secret_backend": {
+		{Version: version.Must(version.NewVersion("0.10.0"))},
+	},
+	"vault_identity_group": {
+		{Version: version.Must(version.NewVersion("0.9.0"))},
+	},
+	"vault_identity_group_alias": {
+		{Version: version.Must(version.NewVersion("0.9.0"))},
+	},
+	"vault_kubernetes_auth_backend_config": {
+		{Version: version.Must(version.NewVersion("0.8.3"))},
+	},
+	"vault_kubernetes_auth_backend_role": {
+		{Version: version.Must(version.NewVersion("0.8.3"))},
+	},
+	"vault_kv_secret_v2_metadata": {
+		{Attribute: "custom_metadata", Version: version.Must(version.NewVersion("1.9.0"))},
+	},
+	"vault_mount": {
+		// Mount options, which select version 2 of the KV secrets engine.
+		{Attribute: "options", Version: version.Must(version.NewVersion("0.10.0"))},
+	},
+}
+
+// kvPreflightMinVersion is the first release to serve
+// sys/internal/ui/mounts, which tells KV version 1 and 2 mounts apart.
+var kvPreflightMinVersion = version.Must(version.NewVersion("0.10.0"))
+
+// readServerVersion returns the version of the Vault server, read from the
+// unauthenticated sys/seal-status endpoint, which also answers on sealed
+// and standby nodes.
+func readServerVersion(client *api.Client) (*version.Version, error) {
+	var status api.SealStatusResponse
+	if err := rootSysRequest(client, "sys/seal-status", nil, &status); err != nil {
+		return nil, fmt.Errorf("error reading Vault server version: %s", err)
+	}
+	if status.Version == "" {
+		return nil, fmt.Errorf("Vault did not report its version")
+	}
+
+	v, err := version.NewVersion(status.Version)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Vault server version %q: %s", status.Version, err)
+	}
+	return v, nil
+}
+
+// rootSysRequest reads one of the sys/ endpoints that are only served in the
+// root namespace, and decodes the response into out.
+func rootSysRequest(client *api.Client, path string, params map[string]string, out interface{}) error {
+	r := client.NewRequest("GET", "/v1/"+path)
+	r.Headers.Del(consts.NamespaceHeaderName)
+	for k, v := range params {
+		r.Params.Set(k, v)
+	}
+
+	resp, err := client.RawRequest(r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+	return resp.DecodeJSON(out)
+}
+
+// checkServerVersion fails the plan of a resource that uses features the
+// connected Vault server doesn't have.
+func checkServerVersion(serverVersion *version.Version, info *terraform.InstanceInfo, s *terraform.InstanceState, diff *terraform.InstanceDiff) error {
+	if serverVersion == nil || diff == nil || diff.Empty() || diff.GetDestroy() {
+		return nil
+	}
+
+	r := &plannedResource{state: s, diff: diff}
+	for _, req := range serverVersionRequirements[info.Type] {
+		if !serverVersion.LessThan(req.Version) {
+			continue
+		}
+		if req.Attribute == "" {
+			if r.IsNew() {
+				return fmt.Errorf("%s requires Vault %s or later, but the server is running %s", info.Type, req.Version, serverVersion)
+			}
+			continue
+		}
+		if plannedAttributeSet(r, req.Attribute) {
+			return fmt.Errorf("%q requires Vault %s or later, but the server is running %s", req.Attribute, req.Version, serverVersion)
+		}
+	}
+
+	return nil
+}
+
+// plannedAttributeSet reports whether the plan gives the attribute, which
+// may be a list or a map, a non-empty value.
+func plannedAttributeSet(r *plannedResource, attribute string) bool {
+	for _, key := range []string{attribute, attribute + ".#", attribute + ".%"} {
+		if !r.HasChange(key) {
+			continue
+		}
+		// Values that are only known during apply get the benefit of the
+		// doubt.
+		if v, known := r.Get(key); known && v != "" && v != "0" && v != "false" {
+			return true
+		}
+	}
+	return false
+}
+
+// kvPreflightNotFound explains a 404 from sys/internal/ui/mounts, given the
+// server version read when the provider was configured. Vault releases
+// before 0.10.0 don't have the endpoint, and only have version 1 of the KV
+// secrets engine. A 404 from a newer server means that something else, such
+// as a proxy, is in the way.
+func kvPreflightNotFound(serverVersion *version.Version, path string, err error) error {
+	if serverVersion == nil {
+		log.Printf("[WARN] KV version preflight for %q returned 404 and the server version is unknown, assuming KV version 1", path)
+		return nil
+	}
+	if serverVersion.LessThan(kvPreflightMinVersion) {
+		log.Printf("[DEBUG] Vault %s predates KV version 2, assuming version 1 for %q", serverVersion, path)
+		return nil
+	}
+	return fmt.Errorf("KV version preflight for %q returned 404, although Vault %s supports it: %s",
+		path, serverVersion, strings.TrimSpace(err.Error()))
+}
@@ -0,0 +1,95 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestServerVersionGating_fakeVault(t *testing.T) {
+	f := newFakeVault(t)
+	f.SetVersion("0.9.6")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_mount" "kv" {
+  path    = "kv"
+  type    = "kv"
+  options = {
+    version = "2"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`"options" requires Vault 0.10.0 or later, but the server is running 0.9.6`),
+			},
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_mount" "kv" {
+  path = "kv"
+  type = "kv"
+}
+`,
+				Check: func(s *terraform.State) error {
+					if !f.HasMount("kv") {
+						return fmt.Errorf("kv/ was not mounted")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestServerVersionGating_fakeVaultResource(t *testing.T) {
+	f := newFakeVault(t)
+	f.SetVersion("0.8.1")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_kubernetes_auth_backend_role" "test" {
+  role_name                        = "test"
+  bound_service_account_names      = ["example"]
+  bound_service_account_namespaces = ["example"]
+}
+`,
+				ExpectError: regexp.MustCompile(`vault_kubernetes_auth_backend_role requires Vault 0.8.3 or later, but the server is running 0.8.1`),
+			},
+		},
+	})
+
+	if n := f.RequestCount("PUT", "auth/kubernetes/role/test"); n != 0 {
+		t.Errorf("expected no role to be written, got %d requests", n)
+	}
+}
+
+func TestServerVersionGating_unknownVersion(t *testing.T) {
+	f := newFakeVault(t)
+	f.InjectFault(fakeVaultFault{Path: "sys/seal-status", Status: 404})
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: f.Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: f.ProviderConfig() + `
+resource "vault_mount" "kv" {
+  path    = "kv"
+  type    = "kv"
+  options = {
+    version = "2"
+  }
+}
+`,
+				Check: resource.TestCheckResourceAttr("vault_mount.kv", "options.version", "2"),
+			},
+		},
+	})
+}
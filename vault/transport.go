@@ -0,0 +1,379 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/logging"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/terraform-providers/terraform-provider-vault/util"
+	"golang.org/x/time/rate"
+)
+
+const redactedValue = "<redacted>"
+
+// sensitiveFieldRegex matches the names of request fields that commonly
+// carry credentials.
+var sensitiveFieldRegex = regexp.MustCompile(`(?i)(password|passphrase|bindpass|secret|token|jwt|private_key|client_key|credentials|pem_bundle|plaintext|ciphertext)`)
+
+// plaintextPathRegex matches sys/ endpoints whose request bodies are made
+// of the values the caller wants to keep out of the logs, such as the input
+// to hash with an audit device.
+var plaintextPathRegex = regexp.MustCompile(`^sys/(audit-hash|tools/hash|wrapping/wrap)(/|$)`)
+
+// redactedHeaders are never written to the logs.
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"X-Vault-Token": true,
+}
+
+// nonIdempotentPathRegex matches endpoints where repeating a write that the
+// server may already have processed has side effects, such as issuing a
+// second token or consuming a single-use wrapping token.
+var nonIdempotentPathRegex = regexp.MustCompile(`(^|/)(login(/.*)?|create(-orphan)?(/.*)?|secret-id|custom-secret-id|unwrap|issue/.+|sign/.+|sign-intermediate|sign-verbatim(/.*)?|generate/.+)$`)
+
+// retryTransport retries Vault requests that failed for reasons that are
+// likely to be transient: rate limiting, failover between nodes, and other
+// server-side errors.
+type retryTransport struct {
+	transport  http.RoundTripper
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+func newRetryTransport(transport http.RoundTripper, maxRetries int, minBackoff, maxBackoff time.Duration) http.RoundTripper {
+	return &retryTransport{
+		transport:  transport,
+		maxRetries: maxRetries,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// sys/health deliberately answers with 429 and 5xx codes to describe
+	// the state of the node.
+	if t.maxRetries <= 0 || strings.HasSuffix(req.URL.Path, "/sys/health") {
+		return t.transport.RoundTrip(req)
+	}
+
+	// The body has to be replayed on each attempt.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idempotent := isIdempotentRequest(req)
+
+	var resp *http.Response
+	err := util.RetryWithBackoff(req.Context(), t.maxRetries, t.minBackoff, t.maxBackoff, func() *resource.RetryError {
+		attempt := new(http.Request)
+		*attempt = *req
+		if body != nil {
+			attempt.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		var err error
+		resp, err = t.transport.RoundTrip(attempt)
+		if err != nil {
+			if idempotent && req.Context().Err() == nil {
+				log.Printf("[DEBUG] Retryable error from %s %s: %s", req.Method, req.URL.Path, err)
+				return resource.RetryableError(err)
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		if !shouldRetryResponse(resp, idempotent) {
+			return nil
+		}
+
+		// Buffer the response so that it can still be handed back to the
+		// caller if this turns out to be the last attempt.
+		respBody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			resp = nil
+			return resource.NonRetryableError(err)
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+		log.Printf("[DEBUG] Retryable response to %s %s: %s", req.Method, req.URL.Path, resp.Status)
+		return resource.RetryableError(fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status))
+	})
+	if err != nil && err == req.Context().Err() {
+		// The request was cancelled while waiting to be retried.
+		return nil, err
+	}
+	if resp != nil {
+		return resp, nil
+	}
+
+	return nil, err
+}
+
+// isIdempotentRequest reports whether req can safely be sent again after
+// the server may have acted on it.
+func isIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "LIST", "OPTIONS":
+		return true
+	}
+	return !nonIdempotentPathRegex.MatchString(strings.TrimSuffix(req.URL.Path, "/"))
+}
+
+// shouldRetryResponse reports whether resp is worth retrying. Requests that
+// aren't idempotent are only retried when Vault rejected them before acting
+// on them.
+func shouldRetryResponse(resp *http.Response, idempotent bool) bool {
+	switch code := resp.StatusCode; {
+	case code == http.StatusPreconditionFailed, code == http.StatusTooManyRequests:
+		return true
+	case code == http.StatusServiceUnavailable:
+		// Sealed and standby nodes turn requests away without handling them.
+		return true
+	case code >= 500 && code != http.StatusNotImplemented:
+		return idempotent
+	}
+	return false
+}
+
+// limitingTransport bounds the number of requests in flight to Vault and
+// the rate at which they are sent. A single limitingTransport is shared by
+// every client the provider creates, so the limits hold however many
+// resources Terraform works on in parallel.
+type limitingTransport struct {
+	transport http.RoundTripper
+
+	// slots holds a value for each request in flight, and is nil when
+	// concurrency isn't limited.
+	slots chan struct{}
+
+	// limiter is nil when the request rate isn't limited.
+	limiter *rate.Limiter
+}
+
+func newLimitingTransport(transport http.RoundTripper, maxConcurrent, perSecond int) http.RoundTripper {
+	if maxConcurrent <= 0 && perSecond <= 0 {
+		return transport
+	}
+
+	t := &limitingTransport{transport: transport}
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+	if perSecond > 0 {
+		t.limiter = rate.NewLimiter(rate.Limit(perSecond), perSecond)
+	}
+	return t
+}
+
+func (t *limitingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		// Vault has finished with the request once its response headers
+		// arrive, so the slot isn't held while the body is read.
+		defer func() { <-t.slots }()
+	}
+	if t.limiter != nil {
+		if err := t.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if waited := time.Since(start); waited > time.Second {
+		log.Printf("[DEBUG] %s %s waited %s for the client-side request limits", req.Method, req.URL.Path, waited)
+	}
+
+	return t.transport.RoundTrip(req)
+}
+
+// redactingTransport logs Vault requests and responses at the DEBUG level
+// without writing out tokens or secret data. It is used in place of the
+// logging.NewTransport dump unless unredacted logging was asked for.
+type redactingTransport struct {
+	transport http.RoundTripper
+}
+
+func newRedactingTransport(transport http.RoundTripper) http.RoundTripper {
+	return &redactingTransport{transport: transport}
+}
+
+func (t *redactingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !logging.IsDebugOrHigher() {
+		return t.transport.RoundTrip(req)
+	}
+
+	reqBody, err := bufferBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] "+redactedLogReqMsg, req.Method, req.URL.Path,
+		redactHeaders(req.Header), redactRequestBody(req.URL.Path, reqBody))
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		log.Printf("[DEBUG] Vault API Request %s %s failed after %s: %s", req.Method, req.URL.Path, elapsed, err)
+		return resp, err
+	}
+
+	respBody, err := bufferBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] "+redactedLogRespMsg, req.Method, req.URL.Path, resp.Status, elapsed,
+		redactResponseBody(respBody))
+
+	return resp, nil
+}
+
+// bufferBody reads the whole of body and replaces it with an equivalent
+// reader, so that it can still be consumed afterwards.
+func bufferBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	b, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = ioutil.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+func redactHeaders(headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		value := strings.Join(headers[name], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = redactedValue
+		}
+		fmt.Fprintf(&buf, "%s: %s\n", name, value)
+	}
+	return buf.String()
+}
+
+// redactRequestBody returns a loggable version of a request body. Requests
+// to sys/, auth/ and identity/ have fields that look like credentials
+// redacted. Anything else is a request to a secrets engine, where the body
+// is frequently the secret itself, so every string value is redacted, as it
+// is for the sys/ endpoints that take plaintext values.
+func redactRequestBody(path string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Sprintf("<redacted %d bytes>", len(body))
+	}
+
+	path = strings.TrimPrefix(path, "/v1/")
+	redactAll := !(strings.HasPrefix(path, "sys/") || strings.HasPrefix(path, "auth/") || strings.HasPrefix(path, "identity/")) ||
+		plaintextPathRegex.MatchString(path)
+
+	return marshalRedacted(redactFields(data, redactAll))
+}
+
+// redactResponseBody returns a loggable version of a response body, with
+// the returned data, tokens and accessors redacted.
+func redactResponseBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Sprintf("<redacted %d bytes>", len(body))
+	}
+
+	if v, ok := data["data"]; ok && v != nil {
+		data["data"] = redactedValue
+	}
+	for _, k := range []string{"auth", "wrap_info"} {
+		if m, ok := data[k].(map[string]interface{}); ok {
+			for _, field := range []string{"client_token", "accessor", "token"} {
+				if _, ok := m[field]; ok {
+					m[field] = redactedValue
+				}
+			}
+		}
+	}
+
+	return marshalRedacted(data)
+}
+
+func redactFields(v interface{}, redactAll bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if k == "data" || sensitiveFieldRegex.MatchString(k) {
+				v[k] = redactedValue
+				continue
+			}
+			v[k] = redactFields(field, redactAll)
+		}
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = redactFields(elem, redactAll)
+		}
+		return v
+	case string:
+		if redactAll {
+			return redactedValue
+		}
+	}
+	return v
+}
+
+func marshalRedacted(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return redactedValue
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+const redactedLogReqMsg = `Vault API Request Details: %s %s
+---[ REQUEST ]---------------------------------------
+%s
+%s
+-----------------------------------------------------`
+
+const redactedLogRespMsg = `Vault API Response Details: %s %s: %s in %s
+---[ RESPONSE ]--------------------------------------
+%s
+-----------------------------------------------------`
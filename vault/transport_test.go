@@ -0,0 +1,381 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/logging"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		statuses      []int
+		maxRetries    int
+		expectStatus  int
+		expectedCalls int
+	}{
+		{"ReadSucceeds", "GET", "/v1/secret/foo", []int{200}, 2, 200, 1},
+		{"ReadRetriedOnServerError", "GET", "/v1/secret/foo", []int{500, 502, 200}, 2, 200, 3},
+		{"ReadRetriesExhausted", "GET", "/v1/secret/foo", []int{500, 500, 500}, 2, 500, 3},
+		{"ReadNotRetriedOnNotFound", "GET", "/v1/secret/foo", []int{404}, 2, 404, 1},
+		{"ReadNotRetriedOnPermissionDenied", "GET", "/v1/secret/foo", []int{403}, 2, 403, 1},
+		{"WriteRetriedOnRateLimit", "PUT", "/v1/secret/foo", []int{429, 200}, 2, 200, 2},
+		{"WriteRetriedOnPreconditionFailed", "PUT", "/v1/secret/foo", []int{412, 200}, 2, 200, 2},
+		{"WriteRetriedOnServerError", "PUT", "/v1/sys/policy/foo", []int{500, 200}, 2, 200, 2},
+		{"LoginNotRetriedOnServerError", "PUT", "/v1/auth/approle/login", []int{500, 200}, 2, 500, 1},
+		{"LoginRetriedOnRateLimit", "PUT", "/v1/auth/approle/login", []int{429, 200}, 2, 200, 2},
+		{"TokenCreateRetriedOnUnavailable", "POST", "/v1/auth/token/create", []int{503, 200}, 2, 200, 2},
+		{"UnwrapNotRetriedOnServerError", "PUT", "/v1/sys/wrapping/unwrap", []int{500, 200}, 2, 500, 1},
+		{"HealthNotRetried", "GET", "/v1/sys/health", []int{429, 200}, 2, 429, 1},
+		{"RetriesDisabled", "GET", "/v1/secret/foo", []int{500, 200}, 0, 500, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Method != "GET" && string(body) != `{"foo":"bar"}` {
+					t.Errorf("attempt %d: bad request body %q", calls, body)
+				}
+				status := tc.statuses[calls]
+				calls++
+				w.WriteHeader(status)
+				w.Write([]byte(`{"errors":["attempt failed"]}`))
+			}))
+			defer server.Close()
+
+			client := &http.Client{
+				Transport: newRetryTransport(http.DefaultTransport, tc.maxRetries, time.Millisecond, 2*time.Millisecond),
+			}
+
+			var body *strings.Reader
+			if tc.method != "GET" {
+				body = strings.NewReader(`{"foo":"bar"}`)
+			} else {
+				body = strings.NewReader("")
+			}
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.expectStatus {
+				t.Errorf("bad status: want %d, got %d", tc.expectStatus, resp.StatusCode)
+			}
+			if calls != tc.expectedCalls {
+				t.Errorf("bad number of requests: want %d, got %d", tc.expectedCalls, calls)
+			}
+			if respBody, err := ioutil.ReadAll(resp.Body); err != nil {
+				t.Fatal(err)
+			} else if string(respBody) != `{"errors":["attempt failed"]}` {
+				t.Errorf("bad response body %q", respBody)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 5, time.Minute, time.Minute)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest("GET", server.URL+"/v1/secret/foo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := client.Do(req.WithContext(ctx)); err == nil {
+		t.Fatal("expected the request waiting to be retried to be cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelling the request took %s", elapsed)
+	}
+}
+
+func TestRedactRequestBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		expected string
+	}{
+		{"Empty", "/v1/sys/policy/foo", "", ""},
+		{"NotJSON", "/v1/sys/policy/foo", "not json", "<redacted 8 bytes>"},
+		{
+			"SysKeepsConfig",
+			"/v1/sys/mounts/aws",
+			`{"type":"aws","config":{"max_lease_ttl":"1h"}}`,
+			`{"config":{"max_lease_ttl":"1h"},"type":"aws"}`,
+		},
+		{
+			"AuthRedactsCredentials",
+			"/v1/auth/approle/login",
+			`{"role_id":"my-role","secret_id":"s3cr3t"}`,
+			`{"role_id":"my-role","secret_id":"<redacted>"}`,
+		},
+		{
+			"AuthRedactsNestedCredentials",
+			"/v1/auth/ldap/config",
+			`{"url":"ldaps://ldap","bindpass":"hunter2","nested":[{"password":"x"}]}`,
+			`{"bindpass":"<redacted>","nested":[{"password":"<redacted>"}],"url":"ldaps://ldap"}`,
+		},
+		{
+			"SecretEngineRedactsStrings",
+			"/v1/secret/foo",
+			`{"username":"admin","password":"hunter2","port":5432,"enabled":true}`,
+			`{"enabled":true,"password":"<redacted>","port":5432,"username":"<redacted>"}`,
+		},
+		{
+			"KVv2RedactsData",
+			"/v1/secret/data/foo",
+			`{"data":{"foo":"bar"},"options":{"cas":1}}`,
+			`{"data":"<redacted>","options":{"cas":1}}`,
+		},
+		{
+			"AuditHashRedactsInput",
+			"/v1/sys/audit-hash/file",
+			`{"input":"hunter2"}`,
+			`{"input":"<redacted>"}`,
+		},
+		{
+			"WrapRedactsStrings",
+			"/v1/sys/wrapping/wrap",
+			`{"password":"hunter2","username":"admin"}`,
+			`{"password":"<redacted>","username":"<redacted>"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := redactRequestBody(tc.path, []byte(tc.body)); got != tc.expected {
+				t.Errorf("bad redacted body:\nwant %s\ngot  %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedactResponseBody(t *testing.T) {
+	body := `{"request_id":"abc","lease_id":"","data":{"password":"hunter2"},` +
+		`"auth":{"client_token":"s.token","accessor":"acc","policies":["default"]},` +
+		`"wrap_info":{"token":"s.wrap","ttl":60}}`
+	expected := `{"auth":{"accessor":"<redacted>","client_token":"<redacted>","policies":["default"]},` +
+		`"data":"<redacted>","lease_id":"","request_id":"abc",` +
+		`"wrap_info":{"token":"<redacted>","ttl":60}}`
+
+	if got := redactResponseBody([]byte(body)); got != expected {
+		t.Errorf("bad redacted body:\nwant %s\ngot  %s", expected, got)
+	}
+	if got := redactResponseBody([]byte(`{"errors":["permission denied"]}`)); got != `{"errors":["permission denied"]}` {
+		t.Errorf("errors should not be redacted, got %s", got)
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-Vault-Token", "s.token")
+	headers.Set("X-Vault-Namespace", "team-a")
+
+	expected := "X-Vault-Namespace: team-a\nX-Vault-Token: <redacted>\n"
+	if got := redactHeaders(headers); got != expected {
+		t.Errorf("bad redacted headers:\nwant %q\ngot  %q", expected, got)
+	}
+}
+
+func TestRedactingTransportPreservesBodies(t *testing.T) {
+	origLog, ok := os.LookupEnv(logging.EnvLog)
+	os.Setenv(logging.EnvLog, "DEBUG")
+	defer func() {
+		if ok {
+			os.Setenv(logging.EnvLog, origLog)
+		} else {
+			os.Unsetenv(logging.EnvLog)
+		}
+	}()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != `{"foo":"bar"}` {
+			t.Errorf("bad request body %q", body)
+		}
+		w.Write([]byte(`{"data":{"foo":"bar"}}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRedactingTransport(http.DefaultTransport)}
+	resp, err := client.Post(server.URL+"/v1/secret/foo", "application/json", strings.NewReader(`{"foo":"bar"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"data":{"foo":"bar"}}` {
+		t.Errorf("bad response body %q", body)
+	}
+}
+
+func TestRedactingTransportAuditHash(t *testing.T) {
+	origLog, ok := os.LookupEnv(logging.EnvLog)
+	os.Setenv(logging.EnvLog, "DEBUG")
+	defer func() {
+		if ok {
+			os.Setenv(logging.EnvLog, origLog)
+		} else {
+			os.Unsetenv(logging.EnvLog)
+		}
+	}()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hash":"hmac-sha256:abc"}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRedactingTransport(http.DefaultTransport)}
+	resp, err := client.Post(server.URL+"/v1/sys/audit-hash/file", "application/json", strings.NewReader(`{"input":"hunter2"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if !strings.Contains(logs.String(), "/v1/sys/audit-hash/file") {
+		t.Fatalf("request not logged:\n%s", logs.String())
+	}
+	if strings.Contains(logs.String(), "hunter2") {
+		t.Errorf("input to hash written to the logs:\n%s", logs.String())
+	}
+}
+
+func TestLimitingTransportConcurrency(t *testing.T) {
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		inFlight--
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newLimitingTransport(http.DefaultTransport, 2, 0)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL + "/v1/sys/mounts")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight != 2 {
+		t.Errorf("bad maximum number of concurrent requests: want 2, got %d", maxInFlight)
+	}
+}
+
+func TestLimitingTransportRate(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newLimitingTransport(http.DefaultTransport, 0, 50)}
+
+	// The first 50 requests use up the burst, and the rest are spaced out
+	// by 20ms.
+	start := time.Now()
+	for i := 0; i < 60; i++ {
+		resp, err := client.Get(server.URL + "/v1/sys/mounts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected requests to be rate limited, 60 took %s", elapsed)
+	}
+	if calls != 60 {
+		t.Errorf("bad number of requests: want 60, got %d", calls)
+	}
+}
+
+func TestLimitingTransportCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{Transport: newLimitingTransport(http.DefaultTransport, 1, 0)}
+
+	go client.Get(server.URL + "/v1/sys/mounts")
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest("GET", server.URL+"/v1/sys/auth", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req.WithContext(ctx)); err == nil {
+		t.Fatal("expected the request waiting for a slot to be cancelled")
+	}
+}
+
+func TestLimitingTransportDisabled(t *testing.T) {
+	if transport := newLimitingTransport(http.DefaultTransport, 0, 0); transport != http.DefaultTransport {
+		t.Errorf("expected no limits to leave the transport unwrapped, got %T", transport)
+	}
+}